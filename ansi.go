@@ -0,0 +1,192 @@
+package interactive
+
+import (
+	"strconv"
+	"strings"
+)
+
+// 解析带有ANSI转义序列的文本, 如git, go test等工具的彩色输出
+// 支持SGR的16色, 256色, 真彩色以及粗体, 斜体, 下划线, 反色, 闪烁, 暗淡和重置, 其他转义序列被丢弃
+
+// 把带有ANSI转义序列的文本转换为StyleAttr和string混合的片段, 可以直接交给SendLineBackWithColor
+func ParseANSI(s string) []interface{} {
+	attr := GetDefaultSytleAttr()
+	segs := []interface{}{attr}
+	var b strings.Builder
+
+	for i := 0; i < len(s); {
+		if s[i] != 0x1b {
+			j := strings.IndexByte(s[i:], 0x1b)
+			if j < 0 {
+				j = len(s) - i
+			}
+			b.WriteString(s[i : i+j])
+			i += j
+			continue
+		}
+
+		// 单独的ESC在末尾, 丢弃
+		if i+1 >= len(s) {
+			break
+		}
+		switch s[i+1] {
+		case '[':
+			// CSI: 参数字节0x30-0x3f, 中间字节0x20-0x2f, 最后一个字节0x40-0x7e
+			j := i + 2
+			for j < len(s) && s[j] >= 0x30 && s[j] <= 0x3f {
+				j++
+			}
+			params := s[i+2 : j]
+			for j < len(s) && s[j] >= 0x20 && s[j] <= 0x2f {
+				j++
+			}
+			// 不完整的序列, 丢弃
+			if j >= len(s) {
+				return flushed(segs, &b)
+			}
+			if s[j] == 'm' {
+				// 样式改变时先把之前的文本放进片段
+				segs = flushed(segs, &b)
+				applySGR(&attr, params)
+				segs = append(segs, attr)
+			}
+			i = j + 1
+		case ']', 'P', '_', '^':
+			// OSC, DCS等字符串序列, 以BEL或者ESC \结束
+			j := i + 2
+			for j < len(s) && s[j] != 0x07 && !(s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\') {
+				j++
+			}
+			if j < len(s) && s[j] == 0x1b {
+				j++
+			}
+			i = j + 1
+		default:
+			// 其他两个字节的序列, 可能带有中间字节, 如ESC ( B
+			j := i + 1
+			for j < len(s) && s[j] >= 0x20 && s[j] <= 0x2f {
+				j++
+			}
+			i = j + 1
+		}
+	}
+	return flushed(segs, &b)
+}
+
+// 把b中的文本放进片段并清空b
+func flushed(segs []interface{}, b *strings.Builder) []interface{} {
+	if b.Len() != 0 {
+		segs = append(segs, b.String())
+		b.Reset()
+	}
+	return segs
+}
+
+// 按SGR参数修改样式, 参数之间用';'分隔, 扩展颜色也可以用':'分隔子参数
+func applySGR(attr *StyleAttr, params string) {
+	if params == "" {
+		params = "0"
+	}
+	fields := strings.Split(params, ";")
+	for k := 0; k < len(fields); k++ {
+		sub := strings.Split(fields[k], ":")
+		code, err := strconv.Atoi(sub[0])
+		if sub[0] == "" {
+			code, err = 0, nil
+		}
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case code == 0:
+			*attr = GetDefaultSytleAttr()
+		case code == 1:
+			attr.Bold = true
+		case code == 2:
+			attr.Dim = true
+		case code == 3:
+			attr.Italic = true
+		case code == 4:
+			attr.Underline = true
+		case code == 5 || code == 6:
+			attr.Blink = true
+		case code == 7:
+			attr.Reverse = true
+		case code == 22:
+			attr.Bold, attr.Dim = false, false
+		case code == 23:
+			attr.Italic = false
+		case code == 24:
+			attr.Underline = false
+		case code == 25:
+			attr.Blink = false
+		case code == 27:
+			attr.Reverse = false
+		case code >= 30 && code <= 37:
+			attr.Foreground = Color(code - 30)
+		case code == 39:
+			attr.Foreground = ColorDefault
+		case code >= 40 && code <= 47:
+			attr.Background = Color(code - 40)
+		case code == 49:
+			attr.Background = ColorDefault
+		case code >= 90 && code <= 97:
+			attr.Foreground = Color(code - 90 + 8)
+		case code >= 100 && code <= 107:
+			attr.Background = Color(code - 100 + 8)
+		case code == 38 || code == 48:
+			// 扩展颜色, 5;n为256色, 2;r;g;b为真彩色
+			var args []string
+			if len(sub) > 1 {
+				args = sub[1:]
+				// ':'分隔的真彩色可能带有颜色空间, 如38:2::r:g:b
+				if len(args) == 5 && args[0] == "2" {
+					args = append([]string{"2"}, args[2:]...)
+				}
+			} else {
+				args = fields[k+1:]
+			}
+			c, n, ok := parseExtendedColor(args)
+			if len(sub) == 1 {
+				k += n
+			}
+			if !ok {
+				continue
+			}
+			if code == 38 {
+				attr.Foreground = c
+			} else {
+				attr.Background = c
+			}
+		}
+	}
+}
+
+// 解析扩展颜色的参数, 返回颜色, 使用的参数个数以及是否合法
+func parseExtendedColor(args []string) (Color, int, bool) {
+	if len(args) == 0 {
+		return ColorDefault, 0, false
+	}
+	atoi := func(s string) (int32, bool) {
+		v, err := strconv.Atoi(s)
+		return int32(v), err == nil && v >= 0 && v <= 255
+	}
+	switch args[0] {
+	case "5":
+		if len(args) < 2 {
+			return ColorDefault, len(args), false
+		}
+		v, ok := atoi(args[1])
+		return Color(v), 2, ok
+	case "2":
+		if len(args) < 4 {
+			return ColorDefault, len(args), false
+		}
+		r, ok1 := atoi(args[1])
+		g, ok2 := atoi(args[2])
+		b, ok3 := atoi(args[3])
+		return NewRGBColor(r, g, b), 4, ok1 && ok2 && ok3
+	}
+	return ColorDefault, 1, false
+}
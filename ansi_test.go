@@ -0,0 +1,67 @@
+package interactive
+
+import (
+	"reflect"
+	"testing"
+)
+
+// 在默认样式的基础上修改, 用于构造期望的片段
+func styled(f func(a *StyleAttr)) StyleAttr {
+	a := GetDefaultSytleAttr()
+	f(&a)
+	return a
+}
+
+func TestParseANSI(t *testing.T) {
+	def := GetDefaultSytleAttr()
+	red := styled(func(a *StyleAttr) { a.Foreground = Color(1) })
+	orange := styled(func(a *StyleAttr) { a.Foreground = Color(208) })
+	rgb := styled(func(a *StyleAttr) { a.Foreground = NewRGBColor(10, 20, 30) })
+	bold := styled(func(a *StyleAttr) { a.Bold = true })
+
+	tests := []struct {
+		name string
+		in   string
+		want []interface{}
+	}{
+		{"plain", "plain", []interface{}{def, "plain"}},
+		{"empty", "", []interface{}{def}},
+		{"16 colors and reset", "\x1b[31mred\x1b[0m ok", []interface{}{def, red, "red", def, " ok"}},
+		{"empty params reset", "\x1b[31m\x1b[mx", []interface{}{def, red, def, "x"}},
+		{"bright colors and attributes", "\x1b[1;4;97;44mx", []interface{}{def, styled(func(a *StyleAttr) {
+			a.Bold, a.Underline, a.Foreground, a.Background = true, true, Color(15), Color(4)
+		}), "x"}},
+		{"22 clears bold and dim", "\x1b[1;2mx\x1b[22my", []interface{}{def, styled(func(a *StyleAttr) {
+			a.Bold, a.Dim = true, true
+		}), "x", def, "y"}},
+		{"default colors", "\x1b[31;42m\x1b[39;49mx", []interface{}{def, styled(func(a *StyleAttr) {
+			a.Foreground, a.Background = Color(1), Color(2)
+		}), def, "x"}},
+		{"256 colors", "\x1b[38;5;208mx", []interface{}{def, orange, "x"}},
+		{"256 colors background", "\x1b[48;5;208mx", []interface{}{def, styled(func(a *StyleAttr) { a.Background = Color(208) }), "x"}},
+		{"true color", "\x1b[38;2;10;20;30mx", []interface{}{def, rgb, "x"}},
+		{"colon true color with color space", "\x1b[38:2::10:20:30mx", []interface{}{def, rgb, "x"}},
+		{"colon true color", "\x1b[38:2:10:20:30mx", []interface{}{def, rgb, "x"}},
+		{"colon does not consume following params", "\x1b[38:5:208;1mx", []interface{}{def, styled(func(a *StyleAttr) {
+			a.Foreground, a.Bold = Color(208), true
+		}), "x"}},
+		{"invalid 256 color is skipped", "\x1b[38;5;300;1mx", []interface{}{def, bold, "x"}},
+		{"truncated true color", "\x1b[38;2;10mx", []interface{}{def, def, "x"}},
+		{"unknown codes are ignored", "\x1b[1;99;200mx", []interface{}{def, bold, "x"}},
+		{"other CSI dropped", "a\x1b[2Kb\x1b[?25lc", []interface{}{def, "abc"}},
+		{"OSC ended by BEL", "a\x1b]0;title\x07b", []interface{}{def, "ab"}},
+		{"OSC ended by ST", "a\x1b]8;;http://x\x1b\\b", []interface{}{def, "ab"}},
+		{"two byte sequence", "a\x1b(Bb\x1b=c", []interface{}{def, "abc"}},
+		{"unterminated CSI", "a\x1b[31", []interface{}{def, "a"}},
+		{"unterminated OSC", "a\x1b]0;title", []interface{}{def, "a"}},
+		{"trailing ESC", "a\x1b", []interface{}{def, "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseANSI(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseANSI(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
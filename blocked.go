@@ -0,0 +1,50 @@
+package interactive
+
+// 输入被阻塞时如何处理用户的按键
+type BlockedInputPolicy int
+
+const (
+	// 丢弃按键, 阻塞状态改变时清空输入
+	BlockedInputDiscard BlockedInputPolicy = iota
+
+	// 继续在本地编辑, 回车推迟到解除阻塞时再提交当前输入, 回车之后输入行不能再编辑
+	BlockedInputBuffer
+
+	// 继续在本地编辑, 回车把命令放入队列并清空输入行, 解除阻塞后按顺序提交
+	// 启用了BlockInputAfterEnter时每次解除阻塞只提交一条
+	BlockedInputQueue
+)
+
+// 阻塞期间是否丢弃编辑输入的按键, 推迟了回车之后输入已经确定, 同样丢弃
+func inputBlocked(w *Win) bool {
+	return w.blockedNow && (w.inputWhileBlocked == BlockedInputDiscard || w.submitHeld)
+}
+
+// 阻塞期间按下回车, 按策略推迟提交或者放入队列
+func holdSubmit(w *Win) {
+	switch w.inputWhileBlocked {
+	case BlockedInputBuffer:
+		w.submitHeld = true
+	case BlockedInputQueue:
+		cmd := string(w.input)
+		w.queuedCmds = append(w.queuedCmds, cmd)
+		recordHistory(w, cmd)
+		inputReset(w)
+		showInput(w)
+	}
+}
+
+// 解除阻塞后提交推迟的输入和队列中的命令, 提交后再次阻塞时剩下的继续等待
+func releaseHeldInput(w *Win) {
+	for len(w.queuedCmds) != 0 && !w.blockedNow {
+		cmd := w.queuedCmds[0]
+		w.queuedCmds = w.queuedCmds[1:]
+		sendCommand(w, cmd)
+	}
+	if w.submitHeld && !w.blockedNow {
+		w.submitHeld = false
+		submitInput(w)
+		return
+	}
+	showInput(w)
+}
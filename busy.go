@@ -0,0 +1,61 @@
+package interactive
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+// 输入被阻塞时的忙碌提示, 解除阻塞后自动恢复原来的命令提示符
+type BusyConfig struct {
+	// 是否启用
+	Enable bool
+
+	// 动画的每一帧, 依次循环显示在命令提示符的位置
+	Frames []string
+
+	// 切换帧的间隔
+	Interval time.Duration
+
+	// 输入为空时显示在输入行的消息, 如"waiting for server…", 为空时不显示
+	Message string
+
+	// 动画和消息的样式
+	Style StyleAttr
+}
+
+func GetDefaultBusyConfig() BusyConfig {
+	return BusyConfig{
+		Enable:   false,
+		Frames:   []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+		Interval: 100 * time.Millisecond,
+		Message:  "",
+		Style:    GetDefaultSytleAttr(),
+	}
+}
+
+// 是否正在显示忙碌提示, 临时读取期间不显示
+func showBusy(w *Win) bool {
+	return w.busy.Enable && len(w.busy.Frames) != 0 && w.blockedNow && w.reader == nil
+}
+
+// 当前帧的片段, 用于代替命令提示符
+func busySegments(w *Win) []interface{} {
+	return []interface{}{w.busy.Style, w.busy.Frames[w.busyFrame%len(w.busy.Frames)]}
+}
+
+// 按是否显示忙碌提示启动或者停止动画的定时器, 没有阻塞时不唤醒事件循环
+func syncBusyTicker(w *Win) {
+	if showBusy(w) && w.busy.Interval > 0 {
+		if w.busyStop == nil {
+			w.busyStop = make(chan struct{})
+			w.busyFrame = 0
+			go runTicker(w, w.busy.Interval, w.busyStop, func() tcell.Event {
+				return &busyTickEvent{when: time.Now()}
+			})
+		}
+	} else if w.busyStop != nil {
+		close(w.busyStop)
+		w.busyStop = nil
+	}
+}
@@ -0,0 +1,189 @@
+package interactive
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+)
+
+// 补全的候选项
+type Candidate struct {
+	// 补全后的文本, 将替换输入中[Start, 光标)的内容
+	Text string
+
+	// 在候选列表中显示的文本, 为空时显示Text
+	Display string
+
+	// 被替换内容的起始位置, 以rune为单位
+	Start int
+}
+
+// 补全器, 在用户按下Tab时调用, 在事件循环中执行, 不应该阻塞
+// input为当前输入, cursor为光标位置, 以rune为单位
+type Completer interface {
+	Complete(input []rune, cursor int) []Candidate
+}
+
+// 使用函数作为补全器
+type CompleterFunc func(input []rune, cursor int) []Candidate
+
+func (f CompleterFunc) Complete(input []rune, cursor int) []Candidate {
+	return f(input, cursor)
+}
+
+// 正在进行的补全, 多个候选项时连续按Tab循环选择
+type completionState struct {
+	candidates []Candidate
+
+	// 当前选中的候选项, -1表示还没有选中
+	index int
+
+	// 开始补全时的输入和光标
+	orig       []rune
+	origCursor int
+}
+
+// 按下Tab, 补全唯一的候选项, 或者补全公共前缀, 或者循环选择候选项
+func completeNext(w *Win, step int) {
+	if w.completion == nil {
+		candidates := w.completer.Complete(append([]rune(nil), w.input...), w.cursor)
+		if len(candidates) == 0 {
+			w.handler.Beep()
+			return
+		}
+		pushUndo(w, editOther)
+
+		st := &completionState{
+			candidates: candidates,
+			index:      -1,
+			orig:       append([]rune(nil), w.input...),
+			origCursor: w.cursor,
+		}
+		if len(candidates) == 1 {
+			completionApply(w, st, candidates[0].Start, []rune(candidates[0].Text))
+			return
+		}
+
+		// 先尝试补全公共前缀, 不能补全时直接开始循环
+		w.completion = st
+		if start, prefix, ok := candidatesCommonPrefix(candidates); ok && len(prefix) > st.origCursor-start {
+			completionApply(w, st, start, prefix)
+			st.orig = append([]rune(nil), w.input...)
+			st.origCursor = w.cursor
+			return
+		}
+	}
+
+	st := w.completion
+	n := len(st.candidates)
+	if st.index == -1 && step < 0 {
+		st.index = n - 1
+	} else {
+		st.index = ((st.index+step)%n + n) % n
+	}
+	c := st.candidates[st.index]
+	completionApply(w, st, c.Start, []rune(c.Text))
+}
+
+// 用text替换开始补全时输入中[start, 光标)的内容
+func completionApply(w *Win, st *completionState, start int, text []rune) {
+	if start < 0 {
+		start = 0
+	}
+	if start > st.origCursor {
+		start = st.origCursor
+	}
+	newInput := make([]rune, 0, len(st.orig)+len(text))
+	newInput = append(newInput, st.orig[:start]...)
+	newInput = append(newInput, text...)
+	newInput = append(newInput, st.orig[st.origCursor:]...)
+	inputSet(w, newInput)
+	w.cursor = start + len(text)
+	endEdit(w)
+}
+
+// 所有候选项的起始位置相同时, 返回它们的公共前缀
+func candidatesCommonPrefix(candidates []Candidate) (int, []rune, bool) {
+	start := candidates[0].Start
+	prefix := []rune(candidates[0].Text)
+	for _, c := range candidates[1:] {
+		if c.Start != start {
+			return 0, nil, false
+		}
+		text := []rune(c.Text)
+		i := 0
+		for i < len(prefix) && i < len(text) && prefix[i] == text[i] {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	return start, prefix, true
+}
+
+// 在输入行上方画候选列表, 覆盖在输出上, 不调用Show
+func drawCompletionList(w *Win) {
+	st := w.completion
+	top := outputRows(w)
+	if st == nil || top == 0 {
+		return
+	}
+
+	// 按屏幕宽度把候选项排成若干行, 每行记录起止下标
+	type listRow struct{ from, to int }
+	var rows []listRow
+	rowWidth := 0
+	for i, c := range st.candidates {
+		width := runewidth.StringWidth(candidateDisplay(c)) + 2
+		if len(rows) == 0 || rowWidth+width > w.curmaxX+1 {
+			rows = append(rows, listRow{from: i, to: i})
+			rowWidth = 0
+		}
+		rows[len(rows)-1].to = i + 1
+		rowWidth += width
+	}
+
+	// 最多占用三分之一的输出行, 显示当前选中项所在的一页
+	maxRows := top / 3
+	if maxRows < 1 {
+		maxRows = 1
+	}
+	page := 0
+	if st.index >= 0 {
+		for i, r := range rows {
+			if st.index >= r.from && st.index < r.to {
+				page = i / maxRows
+				break
+			}
+		}
+	}
+	rows = rows[page*maxRows:]
+	if len(rows) > maxRows {
+		rows = rows[:maxRows]
+	}
+
+	s := w.handler
+	for i, r := range rows {
+		y := top - len(rows) + i
+		for x := 0; x <= w.curmaxX; x++ {
+			s.SetContent(x, y, ' ', nil, tcell.StyleDefault)
+		}
+		x := 0
+		for j := r.from; j < r.to; j++ {
+			style := tcell.StyleDefault
+			if j == st.index {
+				style = style.Reverse(true)
+			}
+			for _, char := range candidateDisplay(st.candidates[j]) {
+				s.SetContent(x, y, char, nil, style)
+				x += runewidth.RuneWidth(char)
+			}
+			x += 2
+		}
+	}
+}
+
+func candidateDisplay(c Candidate) string {
+	if c.Display != "" {
+		return c.Display
+	}
+	return c.Text
+}
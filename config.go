@@ -1,9 +1,25 @@
 package interactive
 
+import "time"
+
 type Config struct {
-	// 命令提示符, 允许使用一个utf8符号
+	// 命令提示符, 允许使用一个utf8符号, 需要多个字符或者多种颜色时使用PromptSegments
 	Prompt rune
 
+	// 多字符的命令提示符, 格式与SendLineBackWithColor的参数相同, 如"[room 3] alice ❯"
+	// 片段开头没有StyleAttr时使用PromptStyle, 不为nil时代替Prompt
+	PromptSegments []interface{}
+
+	// 输入行右侧的提示符, 用于显示状态, 格式与PromptSegments相同, 输入接近它时自动隐藏
+	RightPrompt []interface{}
+
+	// 动态的命令提示符, 每次画命令提示符时在事件循环中调用, 不应该阻塞, 返回值格式与PromptSegments相同
+	// 不为nil时代替Prompt和PromptSegments, 返回的片段不合法时保留上一次的命令提示符
+	PromptFunc func() []interface{}
+
+	// 使用PromptFunc时每隔多久重画一次命令提示符, 用于显示时钟等随时间变化的内容, 0表示只在重画时更新
+	PromptRefreshInterval time.Duration
+
 	// 命令提示符的颜色
 	PromptStyle StyleAttr
 
@@ -13,20 +29,102 @@ type Config struct {
 	// 是否在用户输入内容按回车之后阻塞输入, 这样每次输入命令后由命令接收方决定是否允许用户进一步输入
 	BlockInputAfterEnter bool
 
+	// 输入被阻塞时如何处理用户的按键, 默认丢弃
+	InputWhileBlocked BlockedInputPolicy
+
+	// 输入被阻塞时的忙碌提示, 启用后用动画代替命令提示符
+	Busy BusyConfig
+
+	// 输出行的折行方式, 折行后上下键按屏幕行滚动, GotoLine仍然按逻辑行定位
+	Wrap WrapMode
+
+	// 输出中制表位的宽度, 制表符展开为空格直到下一个制表位, 小于等于0时为8
+	TabWidth int
+
+	// 最多保存的输出行数, 小于等于0表示不限制
+	// 超出时丢弃最早的行, 使用SendLineFront*从前面添加时丢弃最后面的行
+	MaxLines int
+
+	// 输出行被丢弃时调用, 参数为StyleAttr和string混合的片段, 可以用于归档
+	// 在事件循环中调用, 不应该阻塞, 也不能调用Win的方法
+	OnEvict func(line []interface{})
+
 	// 是否在运行后追踪最新的信息
 	TraceAfterRun bool
 
 	// 用来说明需要接收哪些事件
 	EventHandleMask int64
+
+	// 默认左右键在输入行内移动光标, Shift+左右键横向滚动输出
+	// 设置为true时反过来, 左右键横向滚动输出, Shift+左右键移动光标
+	ArrowKeysScrollOutput bool
+
+	// 历史命令, 启用后占用Ctrl+P和Ctrl+N, 上下键仍然用于浏览输出
+	History HistoryConfig
+
+	// 补全器, 设置后Tab用于补全, Shift+Tab反向选择候选项, 为nil时Tab作为Ctrl+I事件
+	Completer Completer
+
+	// 输入的最大长度, 以rune为单位, 0表示不限制, 输入比屏幕宽时输入行横向滚动
+	MaxInputLength int
+
+	// 多行输入模式, 启用后输入区域从命令提示符所在行向上扩展
+	MultiLine MultiLineConfig
+
+	// 是否开启括号粘贴, 开启后粘贴的内容整体插入输入行, 其中的换行不会提交命令, 默认关闭
+	// 开启后会直接向/dev/tty写入控制序列, 退出前需要调用Stop关闭
+	BracketedPaste bool
+
+	// 输入高亮, 每次输入改变后重新计算, 掩码输入时不使用
+	Highlighter Highlighter
+
+	// 输入建议, 光标在输入末尾时在光标后显示建议, 按右键或End接受
+	// 优先使用Suggester, 没有结果时使用最近的以当前输入开头的历史命令(需要启用History)
+	Suggester          Suggester
+	SuggestFromHistory bool
+
+	// 建议的样式
+	SuggestionStyle StyleAttr
+
+	// vi编辑模式, 启用后Esc进入普通模式, 命令提示符后面显示[I]或[N]表示当前模式
+	ViMode bool
+}
+
+func getDefaultSuggestionStyle() StyleAttr {
+	attr := GetDefaultSytleAttr()
+	attr.Foreground = ColorGray
+	attr.Dim = true
+	return attr
 }
 
 func GetDefaultConfig() Config {
 	return Config{
-		Prompt:               '>',
-		PromptStyle:          GetDefaultSytleAttr(),
-		BlockInputAfterRun:   false,
-		BlockInputAfterEnter: false,
-		TraceAfterRun:        false,
-		EventHandleMask:      0,
+		Prompt:                '>',
+		PromptStyle:           GetDefaultSytleAttr(),
+		PromptSegments:        nil,
+		RightPrompt:           nil,
+		PromptFunc:            nil,
+		PromptRefreshInterval: 0,
+		BlockInputAfterRun:    false,
+		BlockInputAfterEnter:  false,
+		InputWhileBlocked:     BlockedInputDiscard,
+		Busy:                  GetDefaultBusyConfig(),
+		Wrap:                  WrapNone,
+		TabWidth:              8,
+		MaxLines:              0,
+		OnEvict:               nil,
+		TraceAfterRun:         false,
+		EventHandleMask:       0,
+		ArrowKeysScrollOutput: false,
+		History:               GetDefaultHistoryConfig(),
+		Completer:             nil,
+		MaxInputLength:        0,
+		MultiLine:             GetDefaultMultiLineConfig(),
+		BracketedPaste:        false,
+		Highlighter:           nil,
+		Suggester:             nil,
+		SuggestFromHistory:    false,
+		SuggestionStyle:       getDefaultSuggestionStyle(),
+		ViMode:                false,
 	}
 }
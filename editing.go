@@ -0,0 +1,188 @@
+package interactive
+
+import (
+	"unicode"
+)
+
+// 输入行的撤销/重做以及剪切环, 只在事件循环中使用
+
+// 撤销栈和剪切环的最大长度
+const maxUndoSteps = 100
+const maxKillRing = 10
+
+// 编辑的种类, 连续键入或者连续删除的字符合并为一步撤销
+type editKind int
+
+const (
+	editOther editKind = iota
+	editInsert
+	editDelete
+)
+
+// 输入的快照
+type inputSnapshot struct {
+	input  []rune
+	cursor int
+}
+
+// 在修改输入之前调用, 保存修改前的状态
+func pushUndo(w *Win, kind editKind) {
+	// 光标停在上一次编辑结束的位置, 并且是同一种编辑时合并
+	if kind != editOther && kind == w.lastEdit && w.cursor == w.lastEditCursor {
+		return
+	}
+
+	w.undoStack = append(w.undoStack, inputSnapshot{input: append([]rune(nil), w.input...), cursor: w.cursor})
+	if len(w.undoStack) > maxUndoSteps {
+		w.undoStack = w.undoStack[len(w.undoStack)-maxUndoSteps:]
+	}
+	w.redoStack = nil
+	w.lastEdit = kind
+}
+
+// 在修改输入之后调用, 记录编辑结束的位置
+func endEdit(w *Win) {
+	w.lastEditCursor = w.cursor
+	w.yank = nil
+}
+
+// 清空撤销/重做记录
+func clearUndo(w *Win) {
+	w.undoStack = nil
+	w.redoStack = nil
+	w.lastEdit = editOther
+	w.yank = nil
+}
+
+// 撤销一步, 没有可以撤销的内容时返回false
+func inputUndo(w *Win) bool {
+	if len(w.undoStack) == 0 {
+		return false
+	}
+	snap := w.undoStack[len(w.undoStack)-1]
+	w.undoStack = w.undoStack[:len(w.undoStack)-1]
+	w.redoStack = append(w.redoStack, inputSnapshot{input: append([]rune(nil), w.input...), cursor: w.cursor})
+	inputSet(w, snap.input)
+	w.cursor = snap.cursor
+	w.lastEdit = editOther
+	w.yank = nil
+	return true
+}
+
+// 重做一步, 没有可以重做的内容时返回false
+func inputRedo(w *Win) bool {
+	if len(w.redoStack) == 0 {
+		return false
+	}
+	snap := w.redoStack[len(w.redoStack)-1]
+	w.redoStack = w.redoStack[:len(w.redoStack)-1]
+	w.undoStack = append(w.undoStack, inputSnapshot{input: append([]rune(nil), w.input...), cursor: w.cursor})
+	inputSet(w, snap.input)
+	w.cursor = snap.cursor
+	w.lastEdit = editOther
+	w.yank = nil
+	return true
+}
+
+// 删除输入中[from, to)的内容并放入剪切环, 掩码输入的内容不放入剪切环
+func inputKill(w *Win, from, to int) bool {
+	if from >= to {
+		return false
+	}
+
+	pushUndo(w, editOther)
+	if !w.masked {
+		w.killRing = append(w.killRing, append([]rune(nil), w.input[from:to]...))
+		if len(w.killRing) > maxKillRing {
+			w.killRing = w.killRing[len(w.killRing)-maxKillRing:]
+		}
+	}
+	w.curwidth -= runesWidth(w.input[from:to])
+	w.input = append(w.input[:from], w.input[to:]...)
+	w.cursor = from
+	endEdit(w)
+	return true
+}
+
+// 剪切从光标到行尾的内容
+func inputKillToEnd(w *Win) bool {
+	lines := inputLines(w)
+	return inputKill(w, w.cursor, lines[cursorLine(lines, w.cursor)].to)
+}
+
+// 剪切光标所在的整行
+func inputKillLine(w *Win) bool {
+	lines := inputLines(w)
+	l := lines[cursorLine(lines, w.cursor)]
+	return inputKill(w, l.from, l.to)
+}
+
+// 剪切光标前的一个单词, 包括单词后面的空白
+func inputKillWordBackward(w *Win) bool {
+	from := w.cursor
+	for from > 0 && unicode.IsSpace(w.input[from-1]) && w.input[from-1] != '\n' {
+		from--
+	}
+	for from > 0 && !unicode.IsSpace(w.input[from-1]) {
+		from--
+	}
+	return inputKill(w, from, w.cursor)
+}
+
+// 最近一次粘贴剪切环的位置, 用于循环粘贴更早的内容
+type yankState struct {
+	from  int
+	to    int
+	index int
+}
+
+// 在光标处粘贴最近剪切的内容, 粘贴总是单独的一步撤销
+func inputYank(w *Win) bool {
+	if len(w.killRing) == 0 {
+		return false
+	}
+	index := len(w.killRing) - 1
+	from := w.cursor
+	pushUndo(w, editOther)
+	yankReplace(w, from, from, w.killRing[index])
+	w.yank = &yankState{from: from, to: w.cursor, index: index}
+	return true
+}
+
+// 紧接着粘贴之后调用, 把刚刚粘贴的内容替换为剪切环中更早的一项
+func inputYankPop(w *Win) bool {
+	y := w.yank
+	if y == nil || len(w.killRing) < 2 {
+		return false
+	}
+
+	index := (y.index - 1 + len(w.killRing)) % len(w.killRing)
+	// 替换之前保存快照, 撤销时回到替换前的粘贴内容
+	pushUndo(w, editOther)
+	yankReplace(w, y.from, y.to, w.killRing[index])
+	w.yank = &yankState{from: y.from, to: w.cursor, index: index}
+	return true
+}
+
+// 把输入中[from, to)的部分替换为rs, 光标移动到rs之后, 不保存撤销快照
+// 超出最大输入长度的部分被丢弃
+func yankReplace(w *Win, from, to int, rs []rune) {
+	if w.maxInputLength > 0 {
+		n := w.maxInputLength - (len(w.input) - (to - from))
+		if n < 0 {
+			n = 0
+		}
+		if len(rs) > n {
+			rs = rs[:n]
+		}
+	}
+
+	newInput := make([]rune, 0, len(w.input)-(to-from)+len(rs))
+	newInput = append(newInput, w.input[:from]...)
+	newInput = append(newInput, rs...)
+	newInput = append(newInput, w.input[to:]...)
+	w.curwidth += runesWidth(rs) - runesWidth(w.input[from:to])
+	w.input = newInput
+	w.cursor = from + len(rs)
+	endEdit(w)
+}
@@ -23,6 +23,8 @@ const EventMaskKeyUpWhenTrace = 2 << 3
 const EventMaskKeyDownWhenTrace = 2 << 4
 
 // 一些控制案按键, 为什么不提供Ctrl+M? 因为它就是Enter, 这个键位有特殊用途, 因此不提供使用
+// Ctrl+K/U/W/Y在没有注册事件时是内置的编辑动作: 剪切到行尾, 剪切整行, 剪切前一个单词, 粘贴剪切的内容
+// 注册了事件之后不再执行内置动作, 而是产生对应的事件
 const EventMaskKeyCtrlSpace = 2 << 5
 const EventMaskKeyCtrlA = 2 << 6
 const EventMaskKeyCtrlB = 2 << 7
@@ -52,6 +54,9 @@ const EventMaskKeyCtrlZ = 2 << 30
 
 const EventMaskWindowResize = 2 << 31
 
+// 开启括号粘贴时, 粘贴的内容不再插入输入行, 而是作为EventPaste交给程序处理
+const EventMaskPaste = 2 << 32
+
 // 上移事件
 type EventMoveUp struct {
 	When                 time.Time
@@ -196,6 +201,12 @@ type EventWindowResize struct {
 	When   time.Time
 }
 
+// 一次粘贴的全部内容, 换行统一为\n
+type EventPaste struct {
+	Text string
+	When time.Time
+}
+
 // 内部事件
 
 type stopEvent struct {
@@ -308,7 +319,7 @@ func (me *popBackLineEvent) When() time.Time {
 
 type sendLineBackWithColorEvent struct {
 	when time.Time
-	data []interface{}
+	data []outputLine
 }
 
 func (me *sendLineBackWithColorEvent) When() time.Time {
@@ -317,7 +328,7 @@ func (me *sendLineBackWithColorEvent) When() time.Time {
 
 type sendLineFrontWithColorEvent struct {
 	when time.Time
-	data []interface{}
+	data []outputLine
 }
 
 func (me *sendLineFrontWithColorEvent) When() time.Time {
@@ -326,7 +337,7 @@ func (me *sendLineFrontWithColorEvent) When() time.Time {
 
 type setPromptEvent struct {
 	when      time.Time
-	dataRune  *rune
+	dataSegs  []interface{}
 	dataStyle *StyleAttr
 }
 
@@ -334,6 +345,177 @@ func (me *setPromptEvent) When() time.Time {
 	return me.when
 }
 
+type setRightPromptEvent struct {
+	when time.Time
+	data []interface{}
+}
+
+func (me *setRightPromptEvent) When() time.Time {
+	return me.when
+}
+
+type setPromptFuncEvent struct {
+	when time.Time
+	data func() []interface{}
+}
+
+func (me *setPromptFuncEvent) When() time.Time {
+	return me.when
+}
+
+// 定时重画命令提示符
+type promptTickEvent struct {
+	when time.Time
+}
+
+func (me *promptTickEvent) When() time.Time {
+	return me.when
+}
+
+// 括号粘贴的识别超时
+type pasteTimeoutEvent struct {
+	when time.Time
+	seq  int
+}
+
+func (me *pasteTimeoutEvent) When() time.Time {
+	return me.when
+}
+
+// 定时切换忙碌提示的帧
+type busyTickEvent struct {
+	when time.Time
+}
+
+func (me *busyTickEvent) When() time.Time {
+	return me.when
+}
+
+type setBusyMessageEvent struct {
+	when time.Time
+	data string
+}
+
+func (me *setBusyMessageEvent) When() time.Time {
+	return me.when
+}
+
+type setInputMaskEvent struct {
+	when   time.Time
+	enable bool
+	mask   rune
+}
+
+func (me *setInputMaskEvent) When() time.Time {
+	return me.when
+}
+
+type readLineEvent struct {
+	when    time.Time
+	prompt  []interface{}
+	masked  bool
+	mask    rune
+	options []string
+	resp    chan readResult
+}
+
+func (me *readLineEvent) When() time.Time {
+	return me.when
+}
+
+type setHighlighterEvent struct {
+	when time.Time
+	data Highlighter
+}
+
+func (me *setHighlighterEvent) When() time.Time {
+	return me.when
+}
+
+type setSuggesterEvent struct {
+	when time.Time
+	data Suggester
+}
+
+func (me *setSuggesterEvent) When() time.Time {
+	return me.when
+}
+
+type setInputEvent struct {
+	when time.Time
+	data string
+}
+
+func (me *setInputEvent) When() time.Time {
+	return me.when
+}
+
+type insertInputEvent struct {
+	when time.Time
+	data string
+}
+
+func (me *insertInputEvent) When() time.Time {
+	return me.when
+}
+
+type updateLineEvent struct {
+	when time.Time
+	id   LineID
+	data []outputLine
+}
+
+func (me *updateLineEvent) When() time.Time {
+	return me.when
+}
+
+type deleteLineEvent struct {
+	when time.Time
+	id   LineID
+}
+
+func (me *deleteLineEvent) When() time.Time {
+	return me.when
+}
+
+type insertAfterEvent struct {
+	when time.Time
+	id   LineID
+	data []outputLine
+}
+
+func (me *insertAfterEvent) When() time.Time {
+	return me.when
+}
+
+type getLineEvent struct {
+	when time.Time
+	id   LineID
+	resp chan []interface{}
+}
+
+func (me *getLineEvent) When() time.Time {
+	return me.when
+}
+
+type getInputEvent struct {
+	when time.Time
+	resp chan string
+}
+
+func (me *getInputEvent) When() time.Time {
+	return me.when
+}
+
+type setCompleterEvent struct {
+	when time.Time
+	data Completer
+}
+
+func (me *setCompleterEvent) When() time.Time {
+	return me.when
+}
+
 type getWindowSizeEventResp struct {
 	height int
 	width  int
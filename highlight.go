@@ -0,0 +1,35 @@
+package interactive
+
+import (
+	"github.com/gdamore/tcell"
+)
+
+// 输入高亮, 参数为当前的输入, 返回StyleAttr和string混合的片段, 与SendLineBackWithColor的参数格式相同
+// 片段中的字符串拼接起来必须与输入相同, 否则不使用高亮
+// 每次输入改变后在事件循环中调用, 不应该阻塞
+type Highlighter func(input string) []interface{}
+
+// 高亮结果的缓存, 输入不变时不重新调用Highlighter
+type highlightCache struct {
+	input  string
+	styles []tcell.Style
+}
+
+// 当前输入每个字符的样式, 没有高亮时返回nil
+func inputStyles(w *Win) []tcell.Style {
+	if w.highlighter == nil || w.masked {
+		return nil
+	}
+
+	input := string(w.input)
+	if w.highlight != nil && w.highlight.input == input {
+		return w.highlight.styles
+	}
+
+	rs, styles, ok := expandSegments(w.highlighter(input))
+	if !ok || string(rs) != input {
+		styles = nil
+	}
+	w.highlight = &highlightCache{input: input, styles: styles}
+	return styles
+}
@@ -0,0 +1,362 @@
+package interactive
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+)
+
+// 历史命令的配置
+type HistoryConfig struct {
+	// 是否启用历史命令, 启用后Ctrl+P回到上一条命令, Ctrl+N前往下一条命令
+	// 注册了EventMaskKeyCtrlP或者EventMaskKeyCtrlN时, 对应的按键仍然产生事件
+	Enable bool
+
+	// 最多保存多少条历史命令, 小于等于0表示不限制
+	MaxSize int
+
+	// 是否忽略与上一条相同的命令
+	IgnoreDups bool
+
+	// 保存历史命令的文件, 为空时不保存, 启动时会从这个文件读取历史命令
+	// 每条新命令追加到文件末尾, 启动时条数超过MaxSize则重写文件, 只保留最新的部分
+	File string
+
+	// 读写历史命令文件失败时调用, 在事件循环中调用, 不应阻塞, 为nil时忽略错误, 文件不存在不算失败
+	OnFileError func(err error)
+
+	// 是否启用Ctrl+R反向增量搜索, 注册了EventMaskKeyCtrlR时Ctrl+R仍然产生事件, 不进行搜索
+	// 搜索时输入内容缩小匹配范围, Ctrl+R查找更早的匹配, 回车接受, Esc或Ctrl+G取消
+	ReverseSearch bool
+}
+
+func GetDefaultHistoryConfig() HistoryConfig {
+	return HistoryConfig{
+		Enable:        false,
+		MaxSize:       500,
+		IgnoreDups:    true,
+		File:          "",
+		OnFileError:   nil,
+		ReverseSearch: true,
+	}
+}
+
+// 历史命令, 只在事件循环中使用
+type inputHistory struct {
+	entries []string
+
+	// 当前浏览的位置, 等于len(entries)时表示正在编辑新的命令
+	index int
+
+	// 开始浏览历史之前正在编辑的内容
+	stash []rune
+
+	maxSize       int
+	ignoreDups    bool
+	file          string
+	onFileError   func(err error)
+	reverseSearch bool
+}
+
+func newInputHistory(cfg HistoryConfig) *inputHistory {
+	h := &inputHistory{
+		maxSize:       cfg.MaxSize,
+		ignoreDups:    cfg.IgnoreDups,
+		file:          cfg.File,
+		onFileError:   cfg.OnFileError,
+		reverseSearch: cfg.ReverseSearch,
+	}
+	if h.file != "" {
+		// 文件不存在或者损坏时当作没有历史
+		if err := h.load(); err != nil && !os.IsNotExist(err) {
+			h.fileError(err)
+		}
+	}
+	h.index = len(h.entries)
+	return h
+}
+
+// 回到编辑新命令的状态
+func (h *inputHistory) reset() {
+	h.index = len(h.entries)
+	h.stash = nil
+}
+
+// 添加一条命令, 并回到编辑新命令的状态
+func (h *inputHistory) add(cmd string) {
+	h.reset()
+	if cmd == "" {
+		return
+	}
+	if h.ignoreDups && len(h.entries) != 0 && h.entries[len(h.entries)-1] == cmd {
+		return
+	}
+
+	h.entries = append(h.entries, cmd)
+	if h.maxSize > 0 && len(h.entries) > h.maxSize {
+		h.entries = h.entries[len(h.entries)-h.maxSize:]
+	}
+	h.index = len(h.entries)
+
+	if h.file != "" {
+		if err := h.appendFile(cmd); err != nil {
+			h.fileError(err)
+		}
+	}
+}
+
+func (h *inputHistory) fileError(err error) {
+	if h.onFileError != nil {
+		h.onFileError(err)
+	}
+}
+
+// 回到上一条命令, current为当前输入, 没有更早的命令时返回false
+func (h *inputHistory) prev(current []rune) ([]rune, bool) {
+	if h.index == 0 {
+		return nil, false
+	}
+	if h.index == len(h.entries) {
+		h.stash = append([]rune(nil), current...)
+	}
+	h.index--
+	return []rune(h.entries[h.index]), true
+}
+
+// 前往下一条命令, 越过最新的一条时返回浏览历史之前的输入
+func (h *inputHistory) next() ([]rune, bool) {
+	if h.index >= len(h.entries) {
+		return nil, false
+	}
+	h.index++
+	if h.index == len(h.entries) {
+		stash := h.stash
+		h.stash = nil
+		return stash, true
+	}
+	return []rune(h.entries[h.index]), true
+}
+
+// 一行一条命令, 使用带引号的格式保存, 这样命令中可以包含换行
+func (h *inputHistory) load() error {
+	f, err := os.Open(h.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+	n := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		cmd, err := strconv.Unquote(line)
+		if err != nil {
+			continue
+		}
+		h.entries = append(h.entries, cmd)
+		n++
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if h.maxSize > 0 && len(h.entries) > h.maxSize {
+		h.entries = h.entries[len(h.entries)-h.maxSize:]
+	}
+	// 追加写入使文件不断变长, 启动时去掉超出的部分
+	if n > len(h.entries) {
+		return h.save()
+	}
+	return nil
+}
+
+// 重写整个文件
+func (h *inputHistory) save() error {
+	var b strings.Builder
+	for _, cmd := range h.entries {
+		b.WriteString(strconv.Quote(cmd))
+		b.WriteByte('\n')
+	}
+	return os.WriteFile(h.file, []byte(b.String()), 0600)
+}
+
+// 把一条命令追加到文件末尾
+func (h *inputHistory) appendFile(cmd string) error {
+	f, err := os.OpenFile(h.file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(strconv.Quote(cmd) + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// 从下标from开始向更早的命令搜索包含query的命令, 返回命令的下标和匹配位置, 没有找到时下标为-1
+func (h *inputHistory) search(query []rune, from int) (int, int) {
+	if from >= len(h.entries) {
+		from = len(h.entries) - 1
+	}
+	q := string(query)
+	for i := from; i >= 0; i-- {
+		if pos := strings.Index(h.entries[i], q); pos >= 0 {
+			return i, utf8.RuneCountInString(h.entries[i][:pos])
+		}
+	}
+	return -1, 0
+}
+
+// 最近的一条以prefix开头并且更长的命令, 返回命令中prefix之后的部分
+func (h *inputHistory) suggest(prefix string) string {
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if len(h.entries[i]) > len(prefix) && strings.HasPrefix(h.entries[i], prefix) {
+			return h.entries[i][len(prefix):]
+		}
+	}
+	return ""
+}
+
+// 反向增量搜索(Ctrl+R)的状态
+type historySearch struct {
+	query []rune
+
+	// 当前匹配的命令下标, -1表示还没有匹配
+	match int
+
+	// 匹配内容在命令中的位置, 以rune为单位
+	matchPos int
+
+	// 最近一次搜索是否失败, 失败时保留上一次的匹配
+	failing bool
+
+	// 开始搜索前的输入, 取消搜索时恢复
+	orig       []rune
+	origCursor int
+}
+
+func startHistorySearch(w *Win) {
+	w.search = &historySearch{
+		match:      -1,
+		orig:       append([]rune(nil), w.input...),
+		origCursor: w.cursor,
+	}
+}
+
+// 在搜索状态下处理按键, 返回false表示结束搜索后这个按键还需要按普通方式处理
+func handleHistorySearchKey(w *Win, event *tcell.EventKey) bool {
+	sr := w.search
+	h := w.history
+	consumed := true
+
+	// 从from开始重新搜索, 找不到时保留原来的匹配
+	research := func(from int) {
+		if len(sr.query) == 0 {
+			sr.match, sr.failing = -1, false
+			return
+		}
+		if i, pos := h.search(sr.query, from); i >= 0 {
+			sr.match, sr.matchPos, sr.failing = i, pos, false
+		} else {
+			sr.failing = true
+		}
+	}
+
+	switch event.Key() {
+	case tcell.KeyRune:
+		sr.query = append(sr.query, event.Rune())
+		if sr.match >= 0 {
+			research(sr.match)
+		} else {
+			research(len(h.entries) - 1)
+		}
+	case tcell.KeyCtrlH, tcell.KeyBackspace2, tcell.KeyETB:
+		if len(sr.query) != 0 {
+			sr.query = sr.query[:len(sr.query)-1]
+		}
+		research(len(h.entries) - 1)
+	case tcell.KeyCtrlR:
+		if len(sr.query) != 0 && sr.match >= 0 {
+			if i, pos := h.search(sr.query, sr.match-1); i >= 0 {
+				sr.match, sr.matchPos, sr.failing = i, pos, false
+			} else {
+				sr.failing = true
+			}
+		}
+	case tcell.KeyEsc, tcell.KeyCtrlG:
+		// 取消搜索, 恢复原来的输入
+		inputSet(w, sr.orig)
+		w.cursor = sr.origCursor
+		w.search = nil
+	default:
+		// 回车接受匹配的命令, 放到输入行中等待编辑或者再次回车
+		// 其他按键接受匹配后按普通方式处理
+		acceptHistorySearch(w)
+		consumed = event.Key() == tcell.KeyEnter
+	}
+
+	showInput(w)
+	return consumed
+}
+
+// 结束搜索, 把匹配的命令放到输入行中, 不重画
+func acceptHistorySearch(w *Win) {
+	sr := w.search
+	if sr.match >= 0 {
+		pushUndo(w, editOther)
+		// 单行模式下命令中的换行变为空格, 与其他外部文本相同
+		rs := sanitizeInput(w, w.history.entries[sr.match])
+		inputSet(w, rs)
+		w.cursor = sr.matchPos
+		if w.cursor > len(rs) {
+			w.cursor = len(rs)
+		}
+	}
+	w.history.reset()
+	w.search = nil
+}
+
+// 画搜索状态下的输入行, 不调用Show
+func drawHistorySearchLine(w *Win) {
+	sr := w.search
+	s := w.handler
+	for i := 0; i <= w.curmaxX; i++ {
+		s.SetContent(i, w.curmaxY, ' ', nil, tcell.StyleDefault)
+	}
+
+	label := "(reverse-i-search)'"
+	if sr.failing {
+		label = "(failed reverse-i-search)'"
+	}
+	label += string(sr.query) + "': "
+
+	offset := 0
+	for _, c := range label {
+		s.SetContent(offset, w.curmaxY, c, nil, styleAttr2TcellStyle(&w.promptStyle))
+		offset += runewidth.RuneWidth(c)
+	}
+
+	cursorX := offset
+	if sr.match >= 0 {
+		for i, c := range []rune(w.history.entries[sr.match]) {
+			if i == sr.matchPos {
+				cursorX = offset
+			}
+			// 多行的命令显示在一行中
+			if c < ' ' || c == 0x7f {
+				c = ' '
+			}
+			s.SetContent(offset, w.curmaxY, c, nil, tcell.StyleDefault)
+			offset += runewidth.RuneWidth(c)
+		}
+	}
+	s.ShowCursor(cursorX, w.curmaxY)
+}
@@ -0,0 +1,363 @@
+package interactive
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+)
+
+// 输入行的编辑操作, 只能在事件循环中调用
+
+// 计算一串字符的显示宽度
+func runesWidth(rs []rune) int {
+	width := 0
+	for _, r := range rs {
+		width += runewidth.RuneWidth(r)
+	}
+	return width
+}
+
+// 在光标处插入字符, 光标移动到插入内容之后
+// 超出最大长度时只插入能放下的部分, 并返回false
+func inputInsert(w *Win, rs []rune) bool {
+	ok := true
+	if w.maxInputLength > 0 && len(w.input)+len(rs) > w.maxInputLength {
+		n := w.maxInputLength - len(w.input)
+		if n < 0 {
+			n = 0
+		}
+		rs = rs[:n]
+		ok = false
+	}
+	// 连续键入的字符合并为一步撤销, 遇到空白时断开
+	if len(rs) == 1 && !unicode.IsSpace(rs[0]) {
+		pushUndo(w, editInsert)
+	} else {
+		pushUndo(w, editOther)
+	}
+
+	newInput := make([]rune, 0, len(w.input)+len(rs))
+	newInput = append(newInput, w.input[:w.cursor]...)
+	newInput = append(newInput, rs...)
+	newInput = append(newInput, w.input[w.cursor:]...)
+	w.input = newInput
+	w.cursor += len(rs)
+	w.curwidth += runesWidth(rs)
+	endEdit(w)
+	return ok
+}
+
+// 删除光标前的一个字符, 没有可删除的字符时返回false
+func inputDeleteBackward(w *Win) bool {
+	if w.cursor == 0 {
+		return false
+	}
+	pushUndo(w, editDelete)
+	w.curwidth -= runewidth.RuneWidth(w.input[w.cursor-1])
+	w.input = append(w.input[:w.cursor-1], w.input[w.cursor:]...)
+	w.cursor--
+	endEdit(w)
+	return true
+}
+
+// 删除光标处的一个字符, 没有可删除的字符时返回false
+func inputDeleteForward(w *Win) bool {
+	if w.cursor == len(w.input) {
+		return false
+	}
+	pushUndo(w, editDelete)
+	w.curwidth -= runewidth.RuneWidth(w.input[w.cursor])
+	w.input = append(w.input[:w.cursor], w.input[w.cursor+1:]...)
+	endEdit(w)
+	return true
+}
+
+// 移动光标, 越界时返回false且不移动
+func inputMoveCursor(w *Win, n int) bool {
+	if w.cursor+n < 0 || w.cursor+n > len(w.input) {
+		return false
+	}
+	w.cursor += n
+	return true
+}
+
+// 把外部来的文本转换为可以放入输入的字符
+// 多行模式下保留换行, 否则换行变为空格, 制表符变为空格, 其他控制字符一律丢弃
+func sanitizeInput(w *Win, text string) []rune {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	rs := make([]rune, 0, len(text))
+	for _, c := range text {
+		switch {
+		case c == '\n' && w.multiLine:
+			rs = append(rs, c)
+		case c == '\n' || c == '\t':
+			rs = append(rs, ' ')
+		case c < ' ' || c == 0x7f:
+		default:
+			rs = append(rs, c)
+		}
+	}
+	return rs
+}
+
+// 从外部修改输入之前调用, 接受正在进行的搜索, 结束正在进行的补全
+func endSearchAndCompletion(w *Win) {
+	if w.search != nil {
+		acceptHistorySearch(w)
+	}
+	if w.completion != nil {
+		w.completion = nil
+		reDraw(w, false)
+	}
+}
+
+// 替换全部输入, 光标移动到末尾
+func inputSet(w *Win, rs []rune) {
+	w.input = append([]rune(nil), rs...)
+	w.cursor = len(w.input)
+	w.curwidth = runesWidth(w.input)
+}
+
+// 清空输入
+func inputReset(w *Win) {
+	w.input = nil
+	w.cursor = 0
+	w.curwidth = 0
+	w.inputOff = 0
+	w.inputLineOff = 0
+	w.viNormal = false
+	w.viPending = 0
+	clearUndo(w)
+}
+
+// 提交当前输入到命令管道, 并清空输入行
+func submitInput(w *Win) {
+	// 正在临时读取输入时, 交给读取者而不是命令管道
+	if w.reader != nil {
+		if !finishRead(w, string(w.input)) {
+			w.handler.Beep()
+		}
+		return
+	}
+
+	// 阻塞期间按策略推迟提交
+	if w.blockedNow {
+		holdSubmit(w)
+		return
+	}
+
+	stringCmd := string(w.input)
+	recordHistory(w, stringCmd)
+	inputReset(w)
+	sendCommand(w, stringCmd)
+	showInput(w)
+}
+
+// 把命令记入历史命令, 掩码输入不记入
+func recordHistory(w *Win, cmd string) {
+	if w.history != nil && !w.masked {
+		w.history.add(cmd)
+	} else if w.history != nil {
+		w.history.reset()
+	}
+	// 历史命令改变了, 建议需要重新计算
+	w.suggestion = nil
+}
+
+// 发送命令到命令管道, 连续发送的命令按顺序到达
+func sendCommand(w *Win, cmd string) {
+	prev := w.cmdSent
+	sent := make(chan struct{})
+	w.cmdSent = sent
+	go func() {
+		if prev != nil {
+			<-prev
+		}
+		w.cmdC <- cmd
+		close(sent)
+	}()
+	if w.blockInputAfterEnter {
+		w.blockedNow = true
+	}
+}
+
+// 重画输入区域并显示, 输入区域的高度改变时重画整个界面
+func showInput(w *Win) {
+	if inputAreaHeight(w) != w.inputHeight {
+		reDraw(w, false)
+		return
+	}
+	drawInputLine(w)
+	w.handler.Show()
+}
+
+// 画输入区域, 包括命令提示符, 已有的输入以及光标, 不调用Show
+func drawInputLine(w *Win) {
+	if w.search != nil {
+		drawHistorySearchLine(w)
+		return
+	}
+
+	// 动态的命令提示符和忙碌提示每次都要重新计算
+	if w.promptFunc != nil || w.busy.Enable {
+		updatePrompt(w)
+	}
+
+	s := w.handler
+	lines := inputLines(w)
+	cl := cursorLine(lines, w.cursor)
+	h := w.inputHeight
+	top := w.curmaxY - h + 1
+
+	// 输入的行数超过输入区域的高度时纵向滚动, 保证光标所在行可见
+	if w.inputLineOff > cl {
+		w.inputLineOff = cl
+	}
+	if w.inputLineOff < cl-h+1 {
+		w.inputLineOff = cl - h + 1
+	}
+	if w.inputLineOff > len(lines)-h {
+		w.inputLineOff = len(lines) - h
+	}
+	if w.inputLineOff < 0 {
+		w.inputLineOff = 0
+	}
+
+	promptStyle := styleAttr2TcellStyle(&w.promptStyle)
+	styles := inputStyles(w)
+	suggestion := inputSuggestion(w)
+	suggestionStyle := styleAttr2TcellStyle(&w.suggestionStyle)
+	startX := inputStartX(w)
+	cursorX, cursorY := startX, top
+	for r := 0; r < h; r++ {
+		y := top + r
+		for i := 0; i <= w.curmaxX; i++ {
+			s.SetContent(i, y, ' ', nil, tcell.StyleDefault)
+		}
+
+		idx := w.inputLineOff + r
+		if idx == 0 {
+			x := drawGlyphs(s, 0, y, w.promptGlyphs, w.curmaxX)
+			if w.viMode {
+				indicator := viInsertIndicator
+				if w.viNormal {
+					indicator = viNormalIndicator
+				}
+				// 与命令提示符之间空一列
+				x++
+				for _, c := range indicator {
+					s.SetContent(x, y, c, nil, promptStyle)
+					x += runewidth.RuneWidth(c)
+				}
+			}
+		} else {
+			s.SetContent(0, y, w.continuationPrompt, nil, promptStyle)
+		}
+		line := w.input[lines[idx].from:lines[idx].to]
+
+		// 只有光标所在行横向滚动, 最后一列留给行尾的光标
+		from, lineCursor := 0, -1
+		if idx == cl {
+			lineCursor = w.cursor - lines[idx].from
+		}
+
+		// 掩码输入时显示掩码字符, 掩码为0时什么也不显示
+		if w.masked {
+			line = maskRunes(line, w.inputMask)
+			if w.inputMask == 0 && lineCursor > 0 {
+				lineCursor = 0
+			}
+		}
+
+		if idx == cl {
+			scrollInput(w, line, lineCursor, w.curmaxX-startX)
+			from = w.inputOff
+			cursorY = y
+		}
+
+		offset := startX
+		for i := from; i < len(line); i++ {
+			c := line[i]
+			if i == lineCursor {
+				cursorX = offset
+			}
+			cWidth := runewidth.RuneWidth(c)
+			if offset+cWidth > w.curmaxX+1 {
+				break
+			}
+			style := tcell.StyleDefault
+			if styles != nil {
+				style = styles[lines[idx].from+i]
+			}
+			s.SetContent(offset, y, c, nil, style)
+			offset += cWidth
+		}
+		if lineCursor == len(line) {
+			cursorX = offset
+
+			// 在光标之后画还没有接受的建议
+			for _, c := range suggestion {
+				cWidth := runewidth.RuneWidth(c)
+				if offset+cWidth > w.curmaxX+1 {
+					break
+				}
+				s.SetContent(offset, y, c, nil, suggestionStyle)
+				offset += cWidth
+			}
+		}
+
+		// 忙碌时输入为空则显示忙碌消息
+		if idx == 0 && len(w.input) == 0 && showBusy(w) && w.busy.Message != "" {
+			rs, styles, _ := expandSegments([]interface{}{w.busy.Style, w.busy.Message})
+			offset = drawGlyphs(s, offset, y, toGlyphs(rs, styles), w.curmaxX)
+		}
+
+		// 在命令提示符所在行的右侧画右侧提示符, 与输入之间至少留一个空格, 放不下时不画
+		if idx == 0 && w.rightPromptGlyphs != nil {
+			rx := w.curmaxX + 1 - w.rightPromptWidth
+			if offset+1 < rx {
+				drawGlyphs(s, rx, y, w.rightPromptGlyphs, w.curmaxX)
+			}
+		}
+	}
+	s.ShowCursor(cursorX, cursorY)
+}
+
+// 把每个字符替换为掩码, 掩码为0时返回空
+func maskRunes(rs []rune, mask rune) []rune {
+	if mask == 0 {
+		return nil
+	}
+	masked := make([]rune, len(rs))
+	for i := range masked {
+		masked[i] = mask
+	}
+	return masked
+}
+
+// 输入开始的列, 命令提示符和vi模式指示之后留一个空格
+func inputStartX(w *Win) int {
+	x := w.promptWidth + 1
+	if w.viMode {
+		x += 1 + runewidth.StringWidth(viInsertIndicator)
+	}
+	return x
+}
+
+// 调整输入的横向偏移, 使光标落在宽度为avail的可见区域内
+func scrollInput(w *Win, line []rune, cursor int, avail int) {
+	if w.inputOff > cursor {
+		w.inputOff = cursor
+	}
+	for w.inputOff < cursor && runesWidth(line[w.inputOff:cursor]) > avail {
+		w.inputOff++
+	}
+	// 删除内容后尽量往回滚, 不在右侧留下多余的空白
+	for w.inputOff > 0 && runesWidth(line[w.inputOff-1:]) <= avail {
+		w.inputOff--
+	}
+}
@@ -0,0 +1,159 @@
+package interactive
+
+// 一行输出及其句柄
+type outputLine struct {
+	id   LineID
+	segs []interface{}
+}
+
+// 输出行的双端队列, 使用环形缓冲区, 两端添加和删除都是O(1), 只在事件循环中使用
+// 同时维护句柄到行的索引, 按句柄查找是O(1)
+type lineBuffer struct {
+	buf  []outputLine
+	head int
+	n    int
+
+	// 句柄到位置的索引, 位置是不随两端添加删除而改变的编号, 第i行的位置为base+i
+	index map[LineID]int
+	base  int
+}
+
+func (b *lineBuffer) len() int {
+	return b.n
+}
+
+// 第i行, 从0开始
+func (b *lineBuffer) get(i int) outputLine {
+	return b.buf[(b.head+i)%len(b.buf)]
+}
+
+// 第i行的内容
+func (b *lineBuffer) at(i int) []interface{} {
+	return b.get(i).segs
+}
+
+func (b *lineBuffer) set(i int, line outputLine) {
+	b.buf[(b.head+i)%len(b.buf)] = line
+	if line.id != 0 {
+		if b.index == nil {
+			b.index = make(map[LineID]int)
+		}
+		b.index[line.id] = b.base + i
+	}
+}
+
+// 第i行将要离开缓冲区, 从索引中删除, 这一行已经被移动到别处时什么也不做
+func (b *lineBuffer) unindex(i int) {
+	id := b.get(i).id
+	if pos, ok := b.index[id]; ok && pos == b.base+i {
+		delete(b.index, id)
+	}
+}
+
+// 容量翻倍, 并把内容移动到缓冲区开头
+func (b *lineBuffer) grow() {
+	size := len(b.buf) * 2
+	if size == 0 {
+		size = 64
+	}
+	buf := make([]outputLine, size)
+	for i := 0; i < b.n; i++ {
+		buf[i] = b.get(i)
+	}
+	b.buf = buf
+	b.head = 0
+}
+
+func (b *lineBuffer) pushBack(line outputLine) {
+	if b.n == len(b.buf) {
+		b.grow()
+	}
+	b.n++
+	b.set(b.n-1, line)
+}
+
+func (b *lineBuffer) pushFront(line outputLine) {
+	if b.n == len(b.buf) {
+		b.grow()
+	}
+	b.head = (b.head - 1 + len(b.buf)) % len(b.buf)
+	b.base--
+	b.n++
+	b.set(0, line)
+}
+
+func (b *lineBuffer) popBack() outputLine {
+	line := b.get(b.n - 1)
+	b.unindex(b.n - 1)
+	b.set(b.n-1, outputLine{})
+	b.n--
+	return line
+}
+
+func (b *lineBuffer) popFront() outputLine {
+	line := b.get(0)
+	b.unindex(0)
+	b.set(0, outputLine{})
+	b.head = (b.head + 1) % len(b.buf)
+	b.base++
+	b.n--
+	return line
+}
+
+// 在第i行之前插入, 需要移动后面的行, O(n)
+func (b *lineBuffer) insert(i int, line outputLine) {
+	b.pushBack(outputLine{})
+	for j := b.n - 1; j > i; j-- {
+		b.set(j, b.get(j-1))
+	}
+	b.set(i, line)
+}
+
+// 删除第i行, 需要移动后面的行, O(n)
+func (b *lineBuffer) remove(i int) {
+	b.unindex(i)
+	for j := i; j < b.n-1; j++ {
+		b.set(j, b.get(j+1))
+	}
+	b.popBack()
+}
+
+// 查找句柄为id的行, 没有找到时返回-1
+func (b *lineBuffer) find(id LineID) int {
+	pos, ok := b.index[id]
+	if !ok {
+		return -1
+	}
+	return pos - b.base
+}
+
+func (b *lineBuffer) clear() {
+	*b = lineBuffer{}
+}
+
+// 超出最大行数时丢弃多余的行, 从前面添加时丢弃最后面的行, 否则丢弃最前面的行
+// 调整浏览的位置, 并把丢弃的行交给OnEvict, 返回是否丢弃了行
+func evictLines(w *Win, fromBack bool) bool {
+	if w.maxLines <= 0 || w.lines.len() <= w.maxLines {
+		return false
+	}
+	for w.lines.len() > w.maxLines {
+		var line outputLine
+		if fromBack {
+			line = w.lines.popBack()
+		} else {
+			line = w.lines.popFront()
+			// 正在浏览的内容向上移动了一行
+			if w.loff >= 1 {
+				w.loff--
+			} else {
+				w.woff = 0
+			}
+		}
+		if w.onEvict != nil {
+			w.onEvict(outputLineSegments(line.segs))
+		}
+	}
+	clampOutputOff(w)
+	return true
+}
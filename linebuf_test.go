@@ -0,0 +1,342 @@
+package interactive
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell"
+)
+
+// 缓冲区中各行的句柄, 按顺序
+func bufferIDs(b *lineBuffer) []LineID {
+	ids := []LineID{}
+	for i := 0; i < b.len(); i++ {
+		ids = append(ids, b.get(i).id)
+	}
+	return ids
+}
+
+func pushBackIDs(b *lineBuffer, ids ...LineID) {
+	for _, id := range ids {
+		b.pushBack(outputLine{id: id})
+	}
+}
+
+func TestLineBuffer(t *testing.T) {
+	// 容量为4, 开头在最后一格, 之后的行从缓冲区开头绕回
+	wrapped := func() *lineBuffer {
+		b := &lineBuffer{buf: make([]outputLine, 4)}
+		b.pushFront(outputLine{id: 1})
+		pushBackIDs(b, 2, 3)
+		return b
+	}
+
+	tests := []struct {
+		name string
+		ops  func(b *lineBuffer)
+		want []LineID
+	}{
+		{"empty", func(b *lineBuffer) {}, []LineID{}},
+		{"push back", func(b *lineBuffer) { pushBackIDs(b, 1, 2, 3) }, []LineID{1, 2, 3}},
+		{"push front", func(b *lineBuffer) {
+			for id := LineID(1); id <= 3; id++ {
+				b.pushFront(outputLine{id: id})
+			}
+		}, []LineID{3, 2, 1}},
+		{"grow keeps order", func(b *lineBuffer) {
+			for id := LineID(1); id <= 100; id++ {
+				b.pushFront(outputLine{id: id})
+			}
+			for i := 0; i < 98; i++ {
+				b.popFront()
+			}
+		}, []LineID{2, 1}},
+		{"pop both ends", func(b *lineBuffer) {
+			pushBackIDs(b, 1, 2, 3, 4)
+			b.popFront()
+			b.popBack()
+		}, []LineID{2, 3}},
+		{"pop to empty and reuse", func(b *lineBuffer) {
+			pushBackIDs(b, 1)
+			b.popBack()
+			b.pushFront(outputLine{id: 2})
+		}, []LineID{2}},
+		{"wrap around", func(b *lineBuffer) {
+			*b = *wrapped()
+		}, []LineID{1, 2, 3}},
+		{"push back after wrap", func(b *lineBuffer) {
+			*b = lineBuffer{buf: make([]outputLine, 4)}
+			pushBackIDs(b, 1, 2, 3)
+			b.popFront()
+			b.popFront()
+			pushBackIDs(b, 4, 5)
+		}, []LineID{3, 4, 5}},
+		{"grow after wrap", func(b *lineBuffer) {
+			*b = *wrapped()
+			pushBackIDs(b, 4, 5)
+		}, []LineID{1, 2, 3, 4, 5}},
+		{"insert across wrap", func(b *lineBuffer) {
+			*b = *wrapped()
+			b.insert(1, outputLine{id: 9})
+		}, []LineID{1, 9, 2, 3}},
+		{"insert at front when full", func(b *lineBuffer) {
+			*b = *wrapped()
+			b.insert(1, outputLine{id: 9})
+			b.insert(0, outputLine{id: 8})
+		}, []LineID{8, 1, 9, 2, 3}},
+		{"insert at end", func(b *lineBuffer) {
+			*b = *wrapped()
+			b.insert(3, outputLine{id: 9})
+		}, []LineID{1, 2, 3, 9}},
+		{"insert into empty", func(b *lineBuffer) {
+			b.insert(0, outputLine{id: 1})
+		}, []LineID{1}},
+		{"remove first across wrap", func(b *lineBuffer) {
+			*b = *wrapped()
+			b.remove(0)
+		}, []LineID{2, 3}},
+		{"remove middle across wrap", func(b *lineBuffer) {
+			*b = *wrapped()
+			b.remove(1)
+		}, []LineID{1, 3}},
+		{"remove last across wrap", func(b *lineBuffer) {
+			*b = *wrapped()
+			b.remove(2)
+		}, []LineID{1, 2}},
+		{"remove then push front", func(b *lineBuffer) {
+			*b = *wrapped()
+			b.remove(1)
+			b.pushFront(outputLine{id: 4})
+			b.insert(2, outputLine{id: 5})
+		}, []LineID{4, 1, 5, 3}},
+		{"pop front many times", func(b *lineBuffer) {
+			for id := LineID(1); id <= 10; id++ {
+				pushBackIDs(b, id)
+				if id%2 == 0 {
+					b.popFront()
+				}
+			}
+		}, []LineID{6, 7, 8, 9, 10}},
+		{"clear", func(b *lineBuffer) {
+			*b = *wrapped()
+			b.clear()
+		}, []LineID{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &lineBuffer{}
+			tt.ops(b)
+			if got := bufferIDs(b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			// 索引与缓冲区中的行一致, 离开缓冲区的行不在索引中
+			for i, id := range tt.want {
+				if got := b.find(id); got != i {
+					t.Errorf("find(%d) = %d, want %d", id, got, i)
+				}
+			}
+			if len(b.index) != len(tt.want) {
+				t.Errorf("index has %d entries, want %d", len(b.index), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestLineBufferPopAndFind(t *testing.T) {
+	b := &lineBuffer{buf: make([]outputLine, 4)}
+	b.pushFront(outputLine{id: 1})
+	pushBackIDs(b, 2, 3)
+
+	tests := []struct {
+		id   LineID
+		want int
+	}{
+		{1, 0},
+		{2, 1},
+		{3, 2},
+		{4, -1},
+		{0, -1},
+	}
+	for _, tt := range tests {
+		if got := b.find(tt.id); got != tt.want {
+			t.Errorf("find(%d) = %d, want %d", tt.id, got, tt.want)
+		}
+	}
+
+	if got := b.popFront().id; got != 1 {
+		t.Errorf("popFront() = %d, want 1", got)
+	}
+	if got := b.popBack().id; got != 3 {
+		t.Errorf("popBack() = %d, want 3", got)
+	}
+	if got := b.find(1); got != -1 {
+		t.Errorf("find(1) after pop = %d, want -1", got)
+	}
+}
+
+// 在模拟终端上运行窗口, 返回窗口和终端
+func newTestWin(t *testing.T, cfg Config) (*Win, tcell.SimulationScreen) {
+	s := tcell.NewSimulationScreen("UTF-8")
+	w := runOnScreen(s, cfg)
+	t.Cleanup(w.Stop)
+	return w, s
+}
+
+// 等待之前发送的事件处理完
+func syncWin(t *testing.T, w *Win) {
+	if _, err := w.GetInput(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 终端第y行的内容, 去掉末尾的空格
+func screenRow(s tcell.SimulationScreen, y int) string {
+	cells, width, _ := s.GetContents()
+	var b strings.Builder
+	for x := 0; x < width; x++ {
+		if rs := cells[y*width+x].Runes; len(rs) != 0 {
+			b.WriteRune(rs[0])
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// 一行输出中的文本
+func segmentsText(segs []interface{}) string {
+	var b strings.Builder
+	for _, v := range segs {
+		if s, ok := v.(string); ok {
+			b.WriteString(s)
+		}
+	}
+	return b.String()
+}
+
+func TestEvictLines(t *testing.T) {
+	tests := []struct {
+		name        string
+		send        func(w *Win)
+		wantRows    []string
+		wantEvicted []string
+	}{
+		{"under limit", func(w *Win) {
+			w.SendLineBack("a")
+			w.SendLineBack("b")
+		}, []string{"a", "b", ""}, nil},
+		{"back evicts front", func(w *Win) {
+			for _, s := range []string{"a", "b", "c", "d", "e"} {
+				w.SendLineBack(s)
+			}
+		}, []string{"c", "d", "e"}, []string{"a", "b"}},
+		{"split lines count separately", func(w *Win) {
+			w.SendLineBack("a\nb\nc\nd")
+		}, []string{"b", "c", "d"}, []string{"a"}},
+		{"front evicts back", func(w *Win) {
+			w.SendLineBack("c")
+			w.SendLineBack("d")
+			w.SendLineFront("a\nb")
+		}, []string{"a", "b", "c"}, []string{"d"}},
+		{"insert evicts front", func(w *Win) {
+			a, _ := w.SendLineBack("a")
+			w.SendLineBack("c")
+			w.SendLineBack("d")
+			w.InsertAfter(a, "b")
+		}, []string{"b", "c", "d"}, []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var evicted []string
+			cfg := GetDefaultConfig()
+			cfg.MaxLines = 3
+			cfg.OnEvict = func(line []interface{}) {
+				evicted = append(evicted, segmentsText(line))
+			}
+			w, s := newTestWin(t, cfg)
+			tt.send(w)
+			syncWin(t, w)
+
+			var rows []string
+			for y := range tt.wantRows {
+				rows = append(rows, screenRow(s, y))
+			}
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("rows = %q, want %q", rows, tt.wantRows)
+			}
+			if !reflect.DeepEqual(evicted, tt.wantEvicted) {
+				t.Errorf("evicted = %q, want %q", evicted, tt.wantEvicted)
+			}
+		})
+	}
+}
+
+// 不追踪最新输出, 浏览较早的内容时丢弃行, 正在浏览的内容不应该跳动
+func TestEvictLinesKeepsView(t *testing.T) {
+	type ids map[string]LineID
+
+	tests := []struct {
+		name        string
+		scroll      func(w *Win)
+		send        func(w *Win, id ids)
+		wantRows    []string
+		wantEvicted []string
+	}{
+		{"back evicts one line above the view", func(w *Win) { w.GotoLine(2) }, func(w *Win, id ids) {
+			w.SendLineBack("g")
+		}, []string{"b", "c", "d"}, []string{"a"}},
+		{"back evicts several lines above the view", func(w *Win) { w.GotoLine(3) }, func(w *Win, id ids) {
+			w.SendLineBack("g\nh")
+		}, []string{"c", "d", "e"}, []string{"a", "b"}},
+		{"back evicts the first visible line", func(w *Win) { w.GotoTop() }, func(w *Win, id ids) {
+			w.SendLineBack("g")
+		}, []string{"b", "c", "d"}, []string{"a"}},
+		{"front evicts below the view", func(w *Win) { w.GotoLine(2) }, func(w *Win, id ids) {
+			w.SendLineFront("z")
+		}, []string{"b", "c", "d"}, []string{"f"}},
+		{"front at the top", func(w *Win) { w.GotoTop() }, func(w *Win, id ids) {
+			w.SendLineFront("y\nz")
+		}, []string{"a", "b", "c"}, []string{"f", "e"}},
+		{"insert above the view", func(w *Win) { w.GotoLine(3) }, func(w *Win, id ids) {
+			w.InsertAfter(id["a"], "a2")
+		}, []string{"c", "d", "e"}, []string{"a"}},
+		{"insert inside the view", func(w *Win) { w.GotoLine(2) }, func(w *Win, id ids) {
+			w.InsertAfter(id["c"], "c2")
+		}, []string{"b", "c", "c2"}, []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var evicted []string
+			cfg := GetDefaultConfig()
+			cfg.MaxLines = 6
+			cfg.OnEvict = func(line []interface{}) {
+				evicted = append(evicted, segmentsText(line))
+			}
+			w, s := newTestWin(t, cfg)
+			// 输出区域3行
+			s.SetSize(20, 4)
+			s.PostEventWait(tcell.NewEventResize(20, 4))
+
+			id := ids{}
+			for _, l := range []string{"a", "b", "c", "d", "e", "f"} {
+				id[l], _ = w.SendLineBack(l)
+			}
+			tt.scroll(w)
+			tt.send(w, id)
+			syncWin(t, w)
+
+			var rows []string
+			for y := range tt.wantRows {
+				rows = append(rows, screenRow(s, y))
+			}
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("rows = %q, want %q", rows, tt.wantRows)
+			}
+			if !reflect.DeepEqual(evicted, tt.wantEvicted) {
+				t.Errorf("evicted = %q, want %q", evicted, tt.wantEvicted)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package interactive
+
+// 输出行的句柄, 由SendLineBack*和InsertAfter返回, 用于之后修改或者删除这一行, 0不是合法的句柄
+type LineID uint64
+
+// 为拆分好的每一行分配句柄, 可以在事件循环以外调用
+func newOutputLines(w *Win, lines [][]interface{}) []outputLine {
+	out := make([]outputLine, len(lines))
+	for k, segs := range lines {
+		out[k] = outputLine{id: LineID(w.lastLineID.Add(1)), segs: segs}
+	}
+	return out
+}
+
+// 在第i行之前插入多行, 插入位置在正在浏览的内容之前时保持浏览的内容不动
+func insertLines(w *Win, i int, lines []outputLine) {
+	for k, line := range lines {
+		w.lines.insert(i+k, line)
+	}
+	if !w.trace && i <= w.loff {
+		w.loff += len(lines)
+	}
+	evictLines(w, false)
+}
+
+// 用多行替换句柄为id的行, 第一行保留原来的句柄, 没有这一行时什么也不做
+func updateLine(w *Win, id LineID, lines []outputLine) {
+	i := w.lines.find(id)
+	if i < 0 {
+		return
+	}
+	lines[0].id = id
+	w.lines.set(i, lines[0])
+	if len(lines) > 1 {
+		insertLines(w, i+1, lines[1:])
+	}
+	clampOutputOff(w)
+	reDraw(w, false)
+}
+
+// 删除句柄为id的行, 删除的行在正在浏览的内容之前时保持浏览的内容不动
+func deleteLine(w *Win, id LineID) {
+	i := w.lines.find(id)
+	if i < 0 {
+		return
+	}
+	w.lines.remove(i)
+	if i < w.loff {
+		w.loff--
+	} else if i == w.loff {
+		w.woff = 0
+	}
+	clampOutputOff(w)
+	reDraw(w, false)
+}
+
+// 在句柄为id的行之后插入多行, 没有这一行时什么也不做
+func insertAfterLine(w *Win, id LineID, lines []outputLine) {
+	i := w.lines.find(id)
+	if i < 0 {
+		return
+	}
+	insertLines(w, i+1, lines)
+	reDraw(w, false)
+}
@@ -0,0 +1,125 @@
+package interactive
+
+import "strings"
+
+// 样式标记, 如"[red::b]check![-] your move"
+// 标记的格式为[前景色:背景色:属性], 每一项都可以省略, 省略时沿用外层的样式, 为"-"时恢复默认
+// 颜色使用ColorNames中的名字或者#ffffff格式, 属性为b粗体, i斜体, u下划线, r反色, l闪烁, d暗淡的组合
+// 标记可以嵌套, [-]结束最近的一个标记, 恢复外层的样式, [[表示字符'[', 不是合法标记的方括号原样保留
+
+// 样式标记的工具, 使用Markup.Parse解析, Markup.Escape转义用户提供的文本
+var Markup markupTool
+
+type markupTool struct{}
+
+// 把带有样式标记的文本转换为StyleAttr和string混合的片段, 可以直接交给SendLineBackWithColor
+func (markupTool) Parse(s string) []interface{} {
+	stack := []StyleAttr{GetDefaultSytleAttr()}
+	segs := []interface{}{stack[0]}
+	var b strings.Builder
+
+	for i := 0; i < len(s); {
+		if s[i] != '[' {
+			j := strings.IndexByte(s[i:], '[')
+			if j < 0 {
+				j = len(s) - i
+			}
+			b.WriteString(s[i : i+j])
+			i += j
+			continue
+		}
+
+		if i+1 < len(s) && s[i+1] == '[' {
+			b.WriteByte('[')
+			i += 2
+			continue
+		}
+		j := strings.IndexByte(s[i:], ']')
+		if j < 0 {
+			b.WriteString(s[i:])
+			break
+		}
+
+		tag := s[i+1 : i+j]
+		if tag == "-" {
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+				segs = flushed(segs, &b)
+				segs = append(segs, stack[len(stack)-1])
+			}
+			i += j + 1
+			continue
+		}
+		attr, ok := parseMarkupTag(stack[len(stack)-1], tag)
+		if !ok {
+			b.WriteByte('[')
+			i++
+			continue
+		}
+		stack = append(stack, attr)
+		segs = flushed(segs, &b)
+		segs = append(segs, attr)
+		i += j + 1
+	}
+	return flushed(segs, &b)
+}
+
+// 转义文本中的'[', 使它在样式标记中原样显示
+func (markupTool) Escape(s string) string {
+	return strings.ReplaceAll(s, "[", "[[")
+}
+
+// 在base的基础上应用一个标记, 不是合法标记时返回false
+func parseMarkupTag(base StyleAttr, tag string) (StyleAttr, bool) {
+	fields := strings.Split(tag, ":")
+	if tag == "" || len(fields) > 3 {
+		return base, false
+	}
+
+	attr := base
+	colors := []*Color{&attr.Foreground, &attr.Background}
+	for k := 0; k < len(fields) && k < 2; k++ {
+		switch name := strings.ToLower(strings.TrimSpace(fields[k])); name {
+		case "":
+		case "-":
+			*colors[k] = ColorDefault
+		default:
+			c := GetColor(name)
+			if c == ColorDefault {
+				return base, false
+			}
+			*colors[k] = c
+		}
+	}
+
+	if len(fields) < 3 {
+		return attr, true
+	}
+	switch flags := fields[2]; flags {
+	case "":
+	case "-":
+		def := GetDefaultSytleAttr()
+		def.Foreground, def.Background = attr.Foreground, attr.Background
+		attr = def
+	default:
+		for _, f := range flags {
+			switch f {
+			case 'b':
+				attr.Bold = true
+			case 'i':
+				attr.Italic = true
+			case 'u':
+				attr.Underline = true
+			case 'r':
+				attr.Reverse = true
+			case 'l':
+				attr.Blink = true
+			case 'd':
+				attr.Dim = true
+			default:
+				return base, false
+			}
+		}
+	}
+	return attr, true
+}
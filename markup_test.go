@@ -0,0 +1,76 @@
+package interactive
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMarkupParse(t *testing.T) {
+	def := GetDefaultSytleAttr()
+	red := styled(func(a *StyleAttr) { a.Foreground = GetColor("red") })
+	redOnBlue := styled(func(a *StyleAttr) { a.Foreground, a.Background = GetColor("red"), GetColor("blue") })
+	redBold := styled(func(a *StyleAttr) { a.Foreground, a.Bold = GetColor("red"), true })
+
+	tests := []struct {
+		name string
+		in   string
+		want []interface{}
+	}{
+		{"plain", "plain", []interface{}{def, "plain"}},
+		{"empty", "", []interface{}{def}},
+		{"color and pop", "[red]x[-]y", []interface{}{def, red, "x", def, "y"}},
+		{"flags", "[red::b]check![-] your move", []interface{}{def, redBold, "check!", def, " your move"}},
+		{"all flags", "[::biurld]x", []interface{}{def, styled(func(a *StyleAttr) {
+			a.Bold, a.Italic, a.Underline, a.Reverse, a.Blink, a.Dim = true, true, true, true, true, true
+		}), "x"}},
+		{"hex color", "[#ff0000]x", []interface{}{def, styled(func(a *StyleAttr) { a.Foreground = GetColor("#ff0000") }), "x"}},
+		{"case and spaces in names", "[ Red ]x", []interface{}{def, red, "x"}},
+		{"nested tags inherit", "[red][:blue]x[-]y[-]z", []interface{}{def, red, redOnBlue, "x", red, "y", def, "z"}},
+		{"dash resets color", "[red:blue][-:]x", []interface{}{def, redOnBlue, styled(func(a *StyleAttr) { a.Background = GetColor("blue") }), "x"}},
+		{"dash resets flags and keeps colors", "[red::bu][::-]x", []interface{}{def, styled(func(a *StyleAttr) {
+			a.Foreground, a.Bold, a.Underline = GetColor("red"), true, true
+		}), red, "x"}},
+		{"double bracket is literal", "[[red]x", []interface{}{def, "[red]x"}},
+		{"unmatched pop is dropped", "[-]x[-]", []interface{}{def, "x"}},
+		{"unknown color is literal", "[nocolor]x", []interface{}{def, "[nocolor]x"}},
+		{"unknown flag is literal", "[::z]x", []interface{}{def, "[::z]x"}},
+		{"empty tag is literal", "[]x", []interface{}{def, "[]x"}},
+		{"too many fields is literal", "[red:blue:b:x]y", []interface{}{def, "[red:blue:b:x]y"}},
+		{"unterminated tag", "a[red", []interface{}{def, "a[red"}},
+		{"literal before tag", "[x] [red]y", []interface{}{def, "[x] ", red, "y"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Markup.Parse(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Markup.Parse(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkupEscape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"plain", "plain"},
+		{"[red]", "[[red]"},
+		{"a[[b]]", "a[[[[b]]"},
+	}
+
+	for _, tt := range tests {
+		if got := Markup.Escape(tt.in); got != tt.want {
+			t.Errorf("Markup.Escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		// 转义后的文本原样显示
+		want := []interface{}{GetDefaultSytleAttr()}
+		if tt.in != "" {
+			want = append(want, tt.in)
+		}
+		if got := Markup.Parse(Markup.Escape(tt.in)); !reflect.DeepEqual(got, want) {
+			t.Errorf("Markup.Parse(Markup.Escape(%q)) = %v, want %v", tt.in, got, want)
+		}
+	}
+}
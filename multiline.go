@@ -0,0 +1,88 @@
+package interactive
+
+// 多行输入模式的配置
+type MultiLineConfig struct {
+	// 是否启用多行输入, 启用后回车插入换行, 使用SubmitKey提交, 提交的命令中包含换行
+	Enable bool
+
+	// 提交输入的按键
+	SubmitKey SubmitKey
+
+	// 输入区域最多占用的行数, 输入行数更多时输入区域纵向滚动
+	MaxHeight int
+
+	// 续行提示符, 显示在第一行以外的输入行前面, 宽度应当与命令提示符相同
+	ContinuationPrompt rune
+}
+
+// 多行输入模式下提交输入的按键
+type SubmitKey int
+
+const (
+	// Ctrl+D提交, 此时Ctrl+D不再产生EventKeyCtrlD事件
+	SubmitKeyCtrlD SubmitKey = iota
+
+	// Alt+回车提交, 部分终端需要开启"Alt作为Meta键"
+	SubmitKeyAltEnter
+)
+
+func GetDefaultMultiLineConfig() MultiLineConfig {
+	return MultiLineConfig{
+		Enable:             false,
+		SubmitKey:          SubmitKeyCtrlD,
+		MaxHeight:          5,
+		ContinuationPrompt: '.',
+	}
+}
+
+// 输入中一行的范围[from, to), 不包含换行符
+type inputRange struct {
+	from int
+	to   int
+}
+
+// 按换行符划分输入, 单行模式下整个输入就是一行
+func inputLines(w *Win) []inputRange {
+	if !w.multiLine {
+		return []inputRange{{from: 0, to: len(w.input)}}
+	}
+
+	var lines []inputRange
+	from := 0
+	for i, c := range w.input {
+		if c == '\n' {
+			lines = append(lines, inputRange{from: from, to: i})
+			from = i + 1
+		}
+	}
+	return append(lines, inputRange{from: from, to: len(w.input)})
+}
+
+// 光标所在的行
+func cursorLine(lines []inputRange, cursor int) int {
+	for i, l := range lines {
+		if cursor >= l.from && cursor <= l.to {
+			return i
+		}
+	}
+	return len(lines) - 1
+}
+
+// 输入区域应当占用的行数, 至少为1, 并且至少给输出留下一行
+func inputAreaHeight(w *Win) int {
+	if !w.multiLine || w.search != nil {
+		return 1
+	}
+
+	h := len(inputLines(w))
+	if h > w.multiLineMaxHeight {
+		h = w.multiLineMaxHeight
+	}
+	if h > w.curmaxY {
+		h = w.curmaxY
+	}
+	if h < 1 {
+		h = 1
+	}
+	return h
+}
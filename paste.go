@@ -0,0 +1,179 @@
+package interactive
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+// 开启括号粘贴模式后, 终端用ESC[200~和ESC[201~包裹粘贴的内容
+// tcell不认识这两个序列, 会把它们拆成Alt+'['以及若干普通字符, 这里把它们重新拼起来
+const pasteStartMarker = "200~"
+const pasteEndMarker = "201~"
+
+// 识别过程中超过这个时间没有收到按键时结束识别, 防止终端丢失结束标记后一直处于粘贴状态
+const pasteTimeout = 100 * time.Millisecond
+
+// 通知终端开启或者关闭括号粘贴模式, 终端不支持时什么也不做
+func setTerminalBracketedPaste(enable bool) {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return
+	}
+	defer tty.Close()
+
+	if enable {
+		tty.WriteString("\x1b[?2004h")
+	} else {
+		tty.WriteString("\x1b[?2004l")
+	}
+}
+
+// 粘贴的识别状态, 只在事件循环中使用
+type pasteState struct {
+	// 是否处于粘贴内容之中
+	pasting bool
+
+	// 可能属于开始或者结束标记的按键, 不匹配时要还原
+	pending []*tcell.EventKey
+
+	// 已经收到的粘贴内容
+	buf []rune
+
+	// 超时的定时器, 以及识别到的按键数, 用于判断超时事件是否已经过期
+	timer *time.Timer
+	seq   int
+}
+
+// 识别括号粘贴, 返回true表示这个按键已经被处理
+func handlePasteKey(w *Win, event *tcell.EventKey) bool {
+	handled := matchPasteKey(w, event)
+
+	// 正在识别时重新开始计时
+	p := &w.paste
+	p.seq++
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	if p.pasting || len(p.pending) != 0 {
+		seq := p.seq
+		p.timer = time.AfterFunc(pasteTimeout, func() {
+			w.handler.PostEvent(&pasteTimeoutEvent{when: time.Now(), seq: seq})
+		})
+	}
+	return handled
+}
+
+func matchPasteKey(w *Win, event *tcell.EventKey) bool {
+	p := &w.paste
+	marker := pasteStartMarker
+	if p.pasting {
+		marker = pasteEndMarker
+	}
+
+	if len(p.pending) == 0 {
+		if event.Key() == tcell.KeyRune && event.Rune() == '[' && event.Modifiers()&tcell.ModAlt != 0 {
+			p.pending = append(p.pending, event)
+			return true
+		}
+		if p.pasting {
+			p.buf = append(p.buf, pasteKeyRunes(event)...)
+			return true
+		}
+		return false
+	}
+
+	// 继续匹配标记
+	if event.Key() == tcell.KeyRune && event.Rune() == rune(marker[len(p.pending)-1]) {
+		p.pending = append(p.pending, event)
+		if len(p.pending) == len(marker)+1 {
+			p.pending = nil
+			if p.pasting {
+				p.pasting = false
+				finishPaste(w, string(p.buf))
+				p.buf = nil
+			} else {
+				p.pasting = true
+			}
+		}
+		return true
+	}
+
+	// 不是标记, 把之前暂存的按键还原
+	pending := p.pending
+	p.pending = nil
+	for _, ev := range pending {
+		if p.pasting {
+			p.buf = append(p.buf, pasteKeyRunes(ev)...)
+		} else {
+			handleKey(w, ev)
+		}
+	}
+	return matchPasteKey(w, event)
+}
+
+// 识别超时, 粘贴中时把已经收到的内容作为一次粘贴结束, 否则把暂存的按键还原
+func flushPaste(w *Win) {
+	p := &w.paste
+	pending := p.pending
+	p.pending = nil
+	p.timer = nil
+	if !p.pasting {
+		for _, ev := range pending {
+			handleKey(w, ev)
+		}
+		return
+	}
+
+	for _, ev := range pending {
+		p.buf = append(p.buf, pasteKeyRunes(ev)...)
+	}
+	text := string(p.buf)
+	p.pasting = false
+	p.buf = nil
+	finishPaste(w, text)
+}
+
+// 粘贴内容中的按键对应的字符
+func pasteKeyRunes(event *tcell.EventKey) []rune {
+	switch event.Key() {
+	case tcell.KeyRune:
+		if event.Modifiers()&tcell.ModAlt != 0 {
+			return []rune{'\x1b', event.Rune()}
+		}
+		return []rune{event.Rune()}
+	case tcell.KeyEnter:
+		return []rune{'\r'}
+	case tcell.KeyLF:
+		return []rune{'\n'}
+	case tcell.KeyTab:
+		return []rune{'\t'}
+	}
+	return nil
+}
+
+// 一次粘贴结束, 注册了EventMaskPaste时交给程序处理, 否则整体插入到输入中
+func finishPaste(w *Win, text string) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	if w.eventMask&EventMaskPaste == EventMaskPaste {
+		go func() {
+			w.specialEventC <- &EventPaste{Text: text, When: time.Now()}
+		}()
+		return
+	}
+
+	if inputBlocked(w) {
+		return
+	}
+
+	endSearchAndCompletion(w)
+	if !inputInsert(w, sanitizeInput(w, text)) {
+		w.handler.Beep()
+	}
+	showInput(w)
+}
@@ -0,0 +1,90 @@
+package interactive
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+)
+
+// 命令提示符由StyleAttr和string混合的片段组成, 片段开头没有StyleAttr时使用promptStyle
+
+// 一个字形, 可能由多个rune组成, 如带变体选择符或者零宽连接符的emoji
+type styledGlyph struct {
+	runes []rune
+	style tcell.Style
+	width int
+}
+
+// 检查片段是否只包含StyleAttr和string
+func checkSegments(segs []interface{}) bool {
+	for _, v := range segs {
+		switch v.(type) {
+		case string, StyleAttr:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// 根据片段和默认样式重新计算命令提示符的字形和宽度
+// 设置了动态的命令提示符时调用它, 临时读取期间使用读取的命令提示符, 忙碌时使用动画
+func updatePrompt(w *Win) {
+	syncBusyTicker(w)
+	segs := w.promptSegs
+	if showBusy(w) {
+		segs = busySegments(w)
+	} else if w.promptFunc != nil && w.reader == nil {
+		if fsegs := w.promptFunc(); checkSegments(fsegs) {
+			segs = fsegs
+		} else if w.promptGlyphs != nil {
+			return
+		}
+	}
+
+	rs, styles, _ := expandSegments(append([]interface{}{w.promptStyle}, segs...))
+	w.promptGlyphs = toGlyphs(rs, styles)
+	w.promptWidth = glyphsWidth(w.promptGlyphs)
+}
+
+// 重新计算右侧提示符的字形和宽度, 默认样式与命令提示符相同
+func updateRightPrompt(w *Win) {
+	rs, styles, _ := expandSegments(append([]interface{}{w.promptStyle}, w.rightPromptSegs...))
+	w.rightPromptGlyphs = toGlyphs(rs, styles)
+	w.rightPromptWidth = glyphsWidth(w.rightPromptGlyphs)
+}
+
+func glyphsWidth(glyphs []styledGlyph) int {
+	width := 0
+	for _, g := range glyphs {
+		width += g.width
+	}
+	return width
+}
+
+// 按字形切分带样式的字符, 每个字形使用它第一个字符的样式
+func toGlyphs(rs []rune, styles []tcell.Style) []styledGlyph {
+	var glyphs []styledGlyph
+	g := uniseg.NewGraphemes(string(rs))
+	i := 0
+	for g.Next() {
+		cluster := g.Runes()
+		// 宽度与tcell一致, 使用字形中第一个非零宽字符的宽度
+		width := runewidth.StringWidth(string(cluster))
+		glyphs = append(glyphs, styledGlyph{runes: cluster, style: styles[i], width: width})
+		i += len(cluster)
+	}
+	return glyphs
+}
+
+// 从x开始画一串字形, 超过maxX的部分不画, 返回画完后的列
+func drawGlyphs(s tcell.Screen, x, y int, glyphs []styledGlyph, maxX int) int {
+	for _, g := range glyphs {
+		if x+g.width > maxX+1 {
+			break
+		}
+		s.SetContent(x, y, g.runes[0], g.runes[1:], g.style)
+		x += g.width
+	}
+	return x
+}
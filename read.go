@@ -0,0 +1,158 @@
+package interactive
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+)
+
+// 临时接管输入行读取一行输入, 读到的内容直接交给调用者, 不发送到命令管道
+
+// 读取的结果, index为选择的选项下标, 不是选择时为-1
+type readResult struct {
+	text  string
+	index int
+}
+
+// 正在进行的读取, 保存读取前的状态以便完成后恢复
+type lineReader struct {
+	resp chan readResult
+
+	// 供选择的选项和当前选中的选项, 没有选项时为普通的读取
+	options  []string
+	selected int
+
+	promptSegs []interface{}
+	masked     bool
+	inputMask  rune
+	blockedNow bool
+	input      []rune
+	cursor     int
+}
+
+// 开始读取, 已经有读取在进行时直接关闭resp
+func beginRead(w *Win, event *readLineEvent) {
+	if w.reader != nil {
+		close(event.resp)
+		return
+	}
+
+	w.reader = &lineReader{
+		resp:       event.resp,
+		options:    event.options,
+		promptSegs: w.promptSegs,
+		masked:     w.masked,
+		inputMask:  w.inputMask,
+		blockedNow: w.blockedNow,
+		input:      w.input,
+		cursor:     w.cursor,
+	}
+	w.promptSegs = event.prompt
+	updatePrompt(w)
+	w.masked, w.inputMask = event.masked, event.mask
+	w.blockedNow = false
+	inputReset(w)
+	if w.history != nil {
+		w.history.reset()
+	}
+	w.search = nil
+	w.completion = nil
+	reDraw(w, false)
+}
+
+// 用户提交了输入, 交给调用者并恢复读取前的状态
+// 选择时输入为空则使用选中的选项, 否则输入必须是选项的序号, 不合法时返回false继续读取
+func finishRead(w *Win, text string) bool {
+	r := w.reader
+	index := -1
+	if r.options != nil {
+		index = r.selected
+		if s := strings.TrimSpace(text); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 || n > len(r.options) {
+				return false
+			}
+			index = n - 1
+		}
+		text = r.options[index]
+	}
+
+	w.reader = nil
+	r.resp <- readResult{text: text, index: index}
+
+	w.promptSegs = r.promptSegs
+	updatePrompt(w)
+	w.masked, w.inputMask = r.masked, r.inputMask
+	w.blockedNow = r.blockedNow
+	inputSet(w, r.input)
+	w.cursor = r.cursor
+	clearUndo(w)
+	reDraw(w, false)
+
+	// 读取期间解除了阻塞时, 提交之前推迟的命令
+	if !w.blockedNow {
+		releaseHeldInput(w)
+	}
+	return true
+}
+
+// 选择时上下键和Tab移动选中的选项, 返回是否处理了这个按键
+func handleSelectKey(w *Win, event *tcell.EventKey) bool {
+	r := w.reader
+	if r == nil || r.options == nil {
+		return false
+	}
+
+	n := len(r.options)
+	switch event.Key() {
+	case tcell.KeyUp, tcell.KeyBacktab, tcell.KeyCtrlP:
+		r.selected = (r.selected - 1 + n) % n
+	case tcell.KeyDown, tcell.KeyTab, tcell.KeyCtrlN:
+		r.selected = (r.selected + 1) % n
+	default:
+		return false
+	}
+	reDraw(w, false)
+	return true
+}
+
+// 在输入行上方画供选择的选项, 覆盖在输出上, 不调用Show
+func drawSelectList(w *Win) {
+	r := w.reader
+	top := outputRows(w)
+	if r == nil || r.options == nil || top == 0 {
+		return
+	}
+
+	// 选项太多时只显示选中项所在的一页
+	rows := len(r.options)
+	if rows > top {
+		rows = top
+	}
+	first := r.selected / rows * rows
+	if first+rows > len(r.options) {
+		first = len(r.options) - rows
+	}
+
+	s := w.handler
+	for i := 0; i < rows; i++ {
+		y := top - rows + i
+		for x := 0; x <= w.curmaxX; x++ {
+			s.SetContent(x, y, ' ', nil, tcell.StyleDefault)
+		}
+		style := tcell.StyleDefault
+		if first+i == r.selected {
+			style = style.Reverse(true)
+		}
+		x := 0
+		for _, c := range strconv.Itoa(first+i+1) + ") " + r.options[first+i] {
+			if x+runewidth.RuneWidth(c) > w.curmaxX+1 {
+				break
+			}
+			s.SetContent(x, y, c, nil, style)
+			x += runewidth.RuneWidth(c)
+		}
+	}
+}
@@ -1070,3 +1070,17 @@ func styleAttr2TcellStyle(attr *StyleAttr) tcell.Style {
 	style = style.Underline(attr.Underline)
 	return style
 }
+
+func tcellStyle2StyleAttr(style tcell.Style) StyleAttr {
+	fg, bg, attrs := style.Decompose()
+	return StyleAttr{
+		Background: Color(bg),
+		Foreground: Color(fg),
+		Blink:      attrs&tcell.AttrBlink != 0,
+		Bold:       attrs&tcell.AttrBold != 0,
+		Dim:        attrs&tcell.AttrDim != 0,
+		Italic:     attrs&tcell.AttrItalic != 0,
+		Reverse:    attrs&tcell.AttrReverse != 0,
+		Underline:  attrs&tcell.AttrUnderline != 0,
+	}
+}
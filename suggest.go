@@ -0,0 +1,58 @@
+package interactive
+
+import (
+	"strings"
+)
+
+// 输入建议, 参数为当前的输入, 返回接在输入后面的建议文本, 没有建议时返回空字符串
+// 每次输入改变后在事件循环中调用, 不应该阻塞
+type Suggester func(input string) string
+
+// 建议结果的缓存, 输入不变时不重新计算
+type suggestionCache struct {
+	input string
+	text  []rune
+}
+
+// 当前的建议, 只在光标位于输入末尾时显示, 没有建议时返回nil
+func inputSuggestion(w *Win) []rune {
+	if len(w.input) == 0 || w.cursor != len(w.input) || w.masked || w.search != nil || w.completion != nil {
+		return nil
+	}
+	if w.suggester == nil && !(w.suggestFromHistory && w.history != nil) {
+		return nil
+	}
+
+	input := string(w.input)
+	if w.suggestion != nil && w.suggestion.input == input {
+		return w.suggestion.text
+	}
+
+	// 优先使用程序提供的建议, 其次使用历史命令
+	text := ""
+	if w.suggester != nil {
+		text = w.suggester(input)
+	}
+	if text == "" && w.suggestFromHistory && w.history != nil {
+		text = w.history.suggest(input)
+	}
+
+	// 建议只显示到第一个换行为止
+	if i := strings.IndexAny(text, "\r\n"); i >= 0 {
+		text = text[:i]
+	}
+	w.suggestion = &suggestionCache{input: input, text: []rune(text)}
+	return w.suggestion.text
+}
+
+// 接受当前的建议, 没有建议时返回false
+func acceptSuggestion(w *Win) bool {
+	text := inputSuggestion(w)
+	if len(text) == 0 {
+		return false
+	}
+	if !inputInsert(w, text) {
+		w.handler.Beep()
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package interactive
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+)
+
+// 默认的制表位宽度
+const defaultTabWidth = 8
+
+// 把发送的一行输出按换行拆成多行, 当前的样式延续到下一行
+// 制表符展开为空格直到下一个制表位, 其他控制字符一律丢弃, "\r\n"因此等同于"\n"
+func splitOutputLines(segs []interface{}, tabWidth int) [][]interface{} {
+	if tabWidth <= 0 {
+		tabWidth = defaultTabWidth
+	}
+
+	var lines [][]interface{}
+	var cur []interface{}
+	var style interface{}
+	col := 0
+	for _, v := range segs {
+		s, ok := v.(string)
+		if !ok {
+			cur = append(cur, v)
+			style = v
+			continue
+		}
+
+		var b strings.Builder
+		for _, c := range s {
+			switch {
+			case c == '\n':
+				if b.Len() != 0 {
+					cur = append(cur, b.String())
+					b.Reset()
+				}
+				lines = append(lines, cur)
+				cur = nil
+				if style != nil {
+					cur = append(cur, style)
+				}
+				col = 0
+			case c == '\t':
+				n := tabWidth - col%tabWidth
+				b.WriteString(strings.Repeat(" ", n))
+				col += n
+			case c < ' ' || c == 0x7f || (c >= 0x80 && c < 0xa0):
+			default:
+				b.WriteRune(c)
+				col += runewidth.RuneWidth(c)
+			}
+		}
+		if b.Len() != 0 {
+			cur = append(cur, b.String())
+		}
+	}
+	return append(lines, cur)
+}
+
+// 把StyleAttr转换为tcell.Style, 检查参数是否只包含StyleAttr和string, 然后按换行拆分
+func prepareOutputLines(w *Win, s []interface{}) ([][]interface{}, bool) {
+	segs := make([]interface{}, len(s))
+	for k, v := range s {
+		switch v := v.(type) {
+		case StyleAttr:
+			segs[k] = styleAttr2TcellStyle(&v)
+		case string:
+			segs[k] = v
+		default:
+			return nil, false
+		}
+	}
+	return splitOutputLines(segs, w.tabWidth), true
+}
+
+// 把保存的一行输出转换回StyleAttr和string混合的片段, 交给程序使用
+func outputLineSegments(line []interface{}) []interface{} {
+	segs := make([]interface{}, len(line))
+	for k, v := range line {
+		if style, ok := v.(tcell.Style); ok {
+			segs[k] = tcellStyle2StyleAttr(style)
+		} else {
+			segs[k] = v
+		}
+	}
+	return segs
+}
@@ -0,0 +1,50 @@
+package interactive
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gdamore/tcell"
+)
+
+func TestSplitOutputLines(t *testing.T) {
+	bold := tcell.StyleDefault.Bold(true)
+	red := tcell.StyleDefault.Foreground(tcell.ColorRed)
+
+	tests := []struct {
+		name     string
+		segs     []interface{}
+		tabWidth int
+		want     [][]interface{}
+	}{
+		{"plain", []interface{}{"abc"}, 8, [][]interface{}{{"abc"}}},
+		{"empty", []interface{}{""}, 8, [][]interface{}{nil}},
+		{"newline", []interface{}{"a\nb"}, 8, [][]interface{}{{"a"}, {"b"}}},
+		{"CRLF", []interface{}{"a\r\nb"}, 8, [][]interface{}{{"a"}, {"b"}}},
+		{"trailing newline adds an empty line", []interface{}{"a\n"}, 8, [][]interface{}{{"a"}, nil}},
+		{"blank line in the middle", []interface{}{"a\n\nb"}, 8, [][]interface{}{{"a"}, nil, {"b"}}},
+		{"default tab", []interface{}{"a\tb"}, 8, [][]interface{}{{"a       b"}}},
+		{"zero tab width uses default", []interface{}{"a\tb"}, 0, [][]interface{}{{"a       b"}}},
+		{"custom tab width", []interface{}{"ab\tc"}, 4, [][]interface{}{{"ab  c"}}},
+		{"tab at a tab stop", []interface{}{"abcd\te"}, 4, [][]interface{}{{"abcd    e"}}},
+		{"tab after wide rune", []interface{}{"中\tx"}, 4, [][]interface{}{{"中  x"}}},
+		{"tab column resets after newline", []interface{}{"abc\n\tx"}, 4, [][]interface{}{{"abc"}, {"    x"}}},
+		{"tab across styled segments", []interface{}{bold, "ab", red, "c\td"}, 8,
+			[][]interface{}{{bold, "ab", red, "c     d"}}},
+		{"tab as a whole segment", []interface{}{"abc", bold, "\t", "x"}, 4,
+			[][]interface{}{{"abc", bold, " ", "x"}}},
+		{"C0 DEL and C1 dropped", []interface{}{"a\x00b\x1bc\x7fd\u0085e\u009bf\rg"}, 8, [][]interface{}{{"abcdefg"}}},
+		{"style carries to next line", []interface{}{bold, "a\nb"}, 8, [][]interface{}{{bold, "a"}, {bold, "b"}}},
+		{"latest style carries", []interface{}{bold, "a", red, "b\nc"}, 8,
+			[][]interface{}{{bold, "a", red, "b"}, {red, "c"}}},
+		{"style before newline only", []interface{}{"a\n", bold}, 8, [][]interface{}{{"a"}, {bold}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitOutputLines(tt.segs, tt.tabWidth); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitOutputLines(%q, %d) = %q, want %q", tt.segs, tt.tabWidth, got, tt.want)
+			}
+		})
+	}
+}
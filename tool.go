@@ -1,6 +1,8 @@
 package interactive
 
 import (
+	"time"
+
 	"github.com/gdamore/tcell"
 	"github.com/mattn/go-runewidth"
 )
@@ -8,61 +10,69 @@ import (
 func reDraw(w *Win, resize bool) {
 	s := w.handler
 	s.Clear()
-	maxLoff, outputLinesN := getMaxLoffAndOutputN(w.curmaxY, len(w.lines))
-	if w.trace || w.loff > maxLoff {
-		w.loff = maxLoff
-	}
+	w.inputHeight = inputAreaHeight(w)
+	if w.wrap != WrapNone {
+		// 折行时按屏幕行定位输出
+		if w.trace {
+			w.loff, w.woff = maxOutputOff(w)
+		} else {
+			clampOutputOff(w)
+		}
+		drawWrappedOutput(w)
+	} else {
+		maxLoff, outputLinesN := getMaxLoffAndOutputN(outputRows(w), w.lines.len())
+		if w.trace || w.loff > maxLoff {
+			w.loff = maxLoff
+		}
 
-	// 开始输出界面
-	for i, j := 0, w.loff; i < outputLinesN; i, j = i+1, j+1 {
-		curwidth := 0
-		curLine := w.lines[j]
-		style := tcell.StyleDefault
+		// 开始输出界面
+		for i, j := 0, w.loff; i < outputLinesN; i, j = i+1, j+1 {
+			curwidth := 0
+			curLine := w.lines.at(j)
+			style := tcell.StyleDefault
 
-		offset := 0
-		for _, v := range curLine {
-			str, ok := v.(string)
-			if ok {
-				for _, char := range str {
-					if offset >= w.coff {
-						charWidth := runewidth.RuneWidth(rune(char))
-						if w.curmaxX+1-curwidth >= charWidth {
-							s.SetContent(curwidth, i, char, nil, style)
-							curwidth += charWidth
-						} else {
-							goto out
+			offset := 0
+			for _, v := range curLine {
+				str, ok := v.(string)
+				if ok {
+					for _, char := range str {
+						if offset >= w.coff {
+							charWidth := runewidth.RuneWidth(rune(char))
+							if w.curmaxX+1-curwidth >= charWidth {
+								s.SetContent(curwidth, i, char, nil, style)
+								curwidth += charWidth
+							} else {
+								goto out
+							}
 						}
+						offset++
 					}
-					offset++
+				} else {
+					style = v.(tcell.Style)
 				}
-			} else {
-				style = v.(tcell.Style)
 			}
+		out:
 		}
-	out:
 	}
 
-	s.SetContent(0, w.curmaxY, w.prompt, nil, styleAttr2TcellStyle(&w.promptStyle))
-	s.SetContent(w.promptWidth, w.curmaxY, ' ', nil, tcell.StyleDefault)
-	ioffset := w.promptWidth + 1
+	// 窗口大小改变时保留输入, 重新计算输入的横向偏移
 	if resize {
-		w.input = nil
-		w.curwidth = 0
-	}
-	for i := 0; i < len(w.input); i++ {
-		s.SetContent(ioffset, w.curmaxY, w.input[i], nil, tcell.StyleDefault)
-		ioffset += runewidth.RuneWidth(w.input[i])
+		w.inputOff = 0
+		w.inputLineOff = 0
 	}
-	s.ShowCursor(ioffset, w.curmaxY)
+	drawCompletionList(w)
+	drawSelectList(w)
+	drawInputLine(w)
 
 	s.Show()
 
 }
 
-func maxwidthfrom(w []([]interface{}), n int) int {
+func maxwidthfrom(lines *lineBuffer, n int) int {
 	maxwidth := 0
 
-	for _, thisLine := range w {
+	for i := 0; i < lines.len(); i++ {
+		thisLine := lines.at(i)
 		thisWidth := 0
 		offset := 0
 		for _, v := range thisLine {
@@ -84,6 +94,32 @@ func maxwidthfrom(w []([]interface{}), n int) int {
 	return maxwidth
 }
 
+// 把StyleAttr和string混合的片段展开为逐个字符及其样式, 出现其他类型时返回false
+func expandSegments(segs []interface{}) ([]rune, []tcell.Style, bool) {
+	var rs []rune
+	var styles []tcell.Style
+	style := tcell.StyleDefault
+	for _, v := range segs {
+		switch v := v.(type) {
+		case string:
+			for _, c := range v {
+				rs = append(rs, c)
+				styles = append(styles, style)
+			}
+		case StyleAttr:
+			style = styleAttr2TcellStyle(&v)
+		default:
+			return nil, nil, false
+		}
+	}
+	return rs, styles, true
+}
+
+// 输出区域的行数, 多行输入时输入区域会占用更多的行
+func outputRows(w *Win) int {
+	return w.curmaxY - w.inputHeight + 1
+}
+
 func getMaxLoffAndOutputN(curY, cntLines int) (x, y int) {
 	if cntLines < curY {
 		x = 0
@@ -94,3 +130,20 @@ func getMaxLoffAndOutputN(curY, cntLines int) (x, y int) {
 	}
 	return x, y
 }
+
+// 每隔interval向事件循环发送一个newEvent创建的事件, 直到窗口停止或者stop被关闭, 用于定时重画
+func runTicker(w *Win, interval time.Duration, stop chan struct{}, newEvent func() tcell.Event) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// 事件队列满时跳过这一次
+			w.handler.PostEvent(newEvent())
+		case <-w.tickStop:
+			return
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package interactive
+
+import (
+	"unicode"
+
+	"github.com/gdamore/tcell"
+)
+
+// vi编辑模式, 只在事件循环中使用
+// 插入模式下按Esc进入普通模式, 普通模式下支持h/l/w/b/0/$移动, x/dw/cw/dd/u编辑, i/a/A回到插入模式
+
+// 显示在命令提示符后面的模式指示, 两者宽度相同
+const viInsertIndicator = "[I]"
+const viNormalIndicator = "[N]"
+
+// 处理vi模式下的按键, 返回true表示已经处理
+func handleViKey(w *Win, event *tcell.EventKey) bool {
+	if inputBlocked(w) {
+		return false
+	}
+
+	if !w.viNormal {
+		if event.Key() != tcell.KeyEsc {
+			return false
+		}
+		// 和vi一样, 回到普通模式时光标左移一格
+		w.viNormal = true
+		w.viPending = 0
+		lines := inputLines(w)
+		if w.cursor > lines[cursorLine(lines, w.cursor)].from {
+			w.cursor--
+		}
+		showInput(w)
+		return true
+	}
+
+	// 普通模式下其他按键按普通方式处理, 比如回车提交
+	if event.Key() != tcell.KeyRune {
+		w.viPending = 0
+		return false
+	}
+
+	lines := inputLines(w)
+	line := lines[cursorLine(lines, w.cursor)]
+	c := event.Rune()
+
+	// 等待d或c的第二个按键
+	if w.viPending != 0 {
+		op := w.viPending
+		w.viPending = 0
+		switch {
+		case op == 'd' && c == 'd':
+			inputKillLine(w)
+		case op == 'd' && c == 'w':
+			inputKill(w, w.cursor, viNextWord(w.input, w.cursor, line.to))
+		case op == 'c' && c == 'w':
+			// 和vi一样, cw在单词上时只修改到单词末尾
+			to := viWordEnd(w.input, w.cursor, line.to)
+			if to == w.cursor {
+				to = viNextWord(w.input, w.cursor, line.to)
+			}
+			inputKill(w, w.cursor, to)
+			w.viNormal = false
+		}
+		viClampCursor(w)
+		showInput(w)
+		return true
+	}
+
+	switch c {
+	case 'h':
+		if w.cursor > line.from {
+			w.cursor--
+		}
+	case 'l':
+		if w.cursor < line.to-1 {
+			w.cursor++
+		}
+	case 'w':
+		w.cursor = viNextWord(w.input, w.cursor, line.to)
+	case 'b':
+		w.cursor = viPrevWord(w.input, w.cursor, line.from)
+	case '0':
+		w.cursor = line.from
+	case '$':
+		w.cursor = line.to
+	case 'x':
+		inputKill(w, w.cursor, minInt(w.cursor+1, line.to))
+	case 'd', 'c':
+		w.viPending = c
+	case 'u':
+		inputUndo(w)
+	case 'i':
+		w.viNormal = false
+	case 'a':
+		if w.cursor < line.to {
+			w.cursor++
+		}
+		w.viNormal = false
+	case 'A':
+		w.cursor = line.to
+		w.viNormal = false
+	}
+	viClampCursor(w)
+	showInput(w)
+	return true
+}
+
+// 普通模式下光标停在字符上, 不能停在行尾之后
+func viClampCursor(w *Win) {
+	if !w.viNormal {
+		return
+	}
+	lines := inputLines(w)
+	line := lines[cursorLine(lines, w.cursor)]
+	if w.cursor == line.to && line.to > line.from {
+		w.cursor--
+	}
+}
+
+// 字符的种类, 0为空白, 1为单词字符, 2为标点, 同类字符组成一个单词
+func viRuneClass(c rune) int {
+	switch {
+	case unicode.IsSpace(c):
+		return 0
+	case c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c):
+		return 1
+	}
+	return 2
+}
+
+// 下一个单词的开头, 不超过end
+func viNextWord(rs []rune, i int, end int) int {
+	i = viWordEnd(rs, i, end)
+	for i < end && viRuneClass(rs[i]) == 0 {
+		i++
+	}
+	return i
+}
+
+// 当前单词的末尾(不包含), 不在单词上时返回i
+func viWordEnd(rs []rune, i int, end int) int {
+	if i >= end {
+		return end
+	}
+	class := viRuneClass(rs[i])
+	if class == 0 {
+		return i
+	}
+	for i < end && viRuneClass(rs[i]) == class {
+		i++
+	}
+	return i
+}
+
+// 上一个单词的开头, 不小于begin
+func viPrevWord(rs []rune, i int, begin int) int {
+	for i > begin && viRuneClass(rs[i-1]) == 0 {
+		i--
+	}
+	if i == begin {
+		return begin
+	}
+	class := viRuneClass(rs[i-1])
+	for i > begin && viRuneClass(rs[i-1]) == class {
+		i--
+	}
+	return i
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
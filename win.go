@@ -2,10 +2,11 @@ package interactive
 
 import (
 	"errors"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gdamore/tcell"
-	"github.com/mattn/go-runewidth"
 )
 
 // 窗口对象, 一个窗口对象可以复用
@@ -15,7 +16,14 @@ type Win struct {
 	handler tcell.Screen
 
 	// 输出行的数据
-	lines []([]interface{})
+	lines lineBuffer
+
+	// 最多保存的输出行数, 超出时丢弃的行交给onEvict
+	maxLines int
+	onEvict  func(line []interface{})
+
+	// 最后分配的输出行句柄, 可以在事件循环以外分配
+	lastLineID atomic.Uint64
 
 	// 输入行的数据
 	input []rune
@@ -23,9 +31,28 @@ type Win struct {
 	// 是否追踪最新输出
 	trace bool
 
-	// 命令提示符
-	prompt      rune
-	promptStyle StyleAttr
+	// 命令提示符的片段和默认样式, 以及展开后的字形
+	promptSegs   []interface{}
+	promptStyle  StyleAttr
+	promptGlyphs []styledGlyph
+
+	// 输入行右侧的提示符
+	rightPromptSegs   []interface{}
+	rightPromptGlyphs []styledGlyph
+	rightPromptWidth  int
+
+	// 动态的命令提示符, 为nil时使用promptSegs
+	promptFunc func() []interface{}
+
+	// 停止定时重画
+	tickStop chan struct{}
+
+	// 输入被阻塞时的忙碌提示, 以及当前显示的帧
+	busy      BusyConfig
+	busyFrame int
+
+	// 忙碌提示的动画定时器, 只在显示忙碌提示时运行, 关闭时停止, 没有运行时为nil
+	busyStop chan struct{}
 
 	// 命令提示符的宽度
 	promptWidth int
@@ -33,12 +60,96 @@ type Win struct {
 	// line offset, 在追踪最新输出时, 这个没意义, 不保护
 	loff int
 
+	// 折行时第loff行中显示在最上面的屏幕行, 不折行时总是0
+	woff int
+
+	// 输出行的折行方式
+	wrap WrapMode
+
+	// 输出中制表位的宽度, 创建后不再改变, 可以在事件循环以外读取
+	tabWidth int
+
 	// column offset
 	coff int
 
 	// 当前输入的宽度
 	curwidth int
 
+	// 输入光标的位置, 以rune为单位, 取值范围[0, len(input)]
+	cursor int
+
+	// 输入行显示的第一个字符的下标, 输入比屏幕宽时横向滚动
+	inputOff int
+
+	// 输入的最大长度, 以rune为单位, 0表示不限制
+	maxInputLength int
+
+	// 多行输入模式的设置
+	multiLine          bool
+	multiLineSubmitKey SubmitKey
+	multiLineMaxHeight int
+	continuationPrompt rune
+
+	// 输入区域当前占用的行数, 单行模式下总是1
+	inputHeight int
+
+	// 是否识别括号粘贴, 以及识别的状态
+	bracketedPaste bool
+	paste          pasteState
+
+	// 是否掩码输入, 以及掩码字符, 掩码为0时不显示输入
+	masked    bool
+	inputMask rune
+
+	// 输入高亮, 以及上一次高亮的结果
+	highlighter Highlighter
+	highlight   *highlightCache
+
+	// 输入建议的来源, 显示的样式, 以及上一次建议的结果
+	suggester          Suggester
+	suggestFromHistory bool
+	suggestionStyle    StyleAttr
+	suggestion         *suggestionCache
+
+	// 撤销/重做记录
+	undoStack      []inputSnapshot
+	redoStack      []inputSnapshot
+	lastEdit       editKind
+	lastEditCursor int
+
+	// 剪切环, 以及最近一次粘贴的位置
+	killRing [][]rune
+	yank     *yankState
+
+	// vi编辑模式, 是否处于普通模式, 以及普通模式下等待第二个按键的d或c
+	viMode    bool
+	viNormal  bool
+	viPending rune
+
+	// 正在进行的临时读取, 没有时为nil
+	reader *lineReader
+
+	// 是否已经通知终端开启括号粘贴模式, 停止时需要关闭, 不在事件循环中使用
+	terminalPasteMode bool
+
+	// 多行输入时输入区域显示的第一行
+	inputLineOff int
+
+	// 左右键是否用于横向滚动输出
+	arrowKeysScrollOutput bool
+
+	// 历史命令, 没有启用时为nil
+	history *inputHistory
+
+	// 补全器, 为nil时Tab作为普通的Ctrl+I事件
+	completer Completer
+
+	// 正在进行的补全, 没有时为nil
+	completion *completionState
+
+	// 正在进行的历史命令搜索, 没有时为nil
+	search *historySearch
+
 	// 当前最大行的偏移, 当前最大列的偏移
 	// 前者=行数-1
 	// 后者=列数-1
@@ -63,6 +174,14 @@ type Win struct {
 	// 目前是否处于输入阻塞状态
 	blockedNow bool
 
+	// 阻塞期间的输入策略, 推迟提交的回车以及排队等待提交的命令
+	inputWhileBlocked BlockedInputPolicy
+	submitHeld        bool
+	queuedCmds        []string
+
+	// 最近一次发送命令完成时关闭, 用于保证命令的顺序
+	cmdSent chan struct{}
+
 	// 用来通知关闭Win以及完成
 	waitStopChan chan struct{}
 }
@@ -70,39 +189,86 @@ type Win struct {
 // 运行窗体
 func Run(cfg Config) *Win {
 	s, _ := tcell.NewScreen()
+	w := runOnScreen(s, cfg)
+	if cfg.BracketedPaste {
+		setTerminalBracketedPaste(true)
+		w.terminalPasteMode = true
+	}
+	return w
+}
+
+// 在给定的Screen上运行窗体
+func runOnScreen(s tcell.Screen, cfg Config) *Win {
 	s.Init()
 	x, y := s.Size()
 	w := &Win{
-		handler:              s,
-		lines:                nil,
-		input:                nil,
-		trace:                cfg.TraceAfterRun,
-		prompt:               cfg.Prompt,
-		promptStyle:          cfg.PromptStyle,
-		promptWidth:          runewidth.RuneWidth(cfg.Prompt),
-		loff:                 0,
-		coff:                 0,
-		curwidth:             0,
-		curmaxY:              y - 1,
-		curmaxX:              x - 1,
-		cmdC:                 make(chan string),
-		isStopped:            false,
-		blockInputAfterEnter: cfg.BlockInputAfterEnter,
-		blockedNow:           cfg.BlockInputAfterRun,
-		waitStopChan:         make(chan struct{}),
-		specialEventC:        make(chan interface{}),
-		eventMask:            cfg.EventHandleMask,
+		handler:               s,
+		maxLines:              cfg.MaxLines,
+		onEvict:               cfg.OnEvict,
+		input:                 nil,
+		trace:                 cfg.TraceAfterRun,
+		promptSegs:            []interface{}{string(cfg.Prompt)},
+		promptStyle:           cfg.PromptStyle,
+		loff:                  0,
+		coff:                  0,
+		wrap:                  cfg.Wrap,
+		tabWidth:              cfg.TabWidth,
+		curwidth:              0,
+		cursor:                0,
+		arrowKeysScrollOutput: cfg.ArrowKeysScrollOutput,
+		curmaxY:               y - 1,
+		curmaxX:               x - 1,
+		cmdC:                  make(chan string),
+		isStopped:             false,
+		blockInputAfterEnter:  cfg.BlockInputAfterEnter,
+		blockedNow:            cfg.BlockInputAfterRun,
+		inputWhileBlocked:     cfg.InputWhileBlocked,
+		waitStopChan:          make(chan struct{}),
+		specialEventC:         make(chan interface{}),
+		eventMask:             cfg.EventHandleMask,
+		completer:             cfg.Completer,
+		maxInputLength:        cfg.MaxInputLength,
+		multiLine:             cfg.MultiLine.Enable,
+		multiLineSubmitKey:    cfg.MultiLine.SubmitKey,
+		multiLineMaxHeight:    cfg.MultiLine.MaxHeight,
+		continuationPrompt:    cfg.MultiLine.ContinuationPrompt,
+		inputHeight:           1,
+		bracketedPaste:        cfg.BracketedPaste,
+		highlighter:           cfg.Highlighter,
+		suggester:             cfg.Suggester,
+		suggestFromHistory:    cfg.SuggestFromHistory,
+		suggestionStyle:       cfg.SuggestionStyle,
+		viMode:                cfg.ViMode,
+		promptFunc:            cfg.PromptFunc,
+		tickStop:              make(chan struct{}),
+		busy:                  cfg.Busy,
+	}
+
+	if cfg.History.Enable {
+		w.history = newInputHistory(cfg.History)
 	}
+	if cfg.PromptSegments != nil && checkSegments(cfg.PromptSegments) {
+		w.promptSegs = append([]interface{}{}, cfg.PromptSegments...)
+	}
+	updatePrompt(w)
+	if checkSegments(cfg.RightPrompt) {
+		w.rightPromptSegs = append([]interface{}{}, cfg.RightPrompt...)
+	}
+	updateRightPrompt(w)
 
 	// 开始先画一个命令提示符出来
 	s.SetStyle(tcell.StyleDefault)
 	s.Clear()
-	s.SetContent(0, w.curmaxY, cfg.Prompt, nil, styleAttr2TcellStyle(&w.promptStyle))
-	s.ShowCursor(w.promptWidth+1, w.curmaxY)
+	drawInputLine(w)
 	s.Show()
 
 	// 开始事件监听
 	go doListen(w)
+	if cfg.PromptRefreshInterval > 0 {
+		go runTicker(w, cfg.PromptRefreshInterval, nil, func() tcell.Event {
+			return &promptTickEvent{when: time.Now()}
+		})
+	}
 	return w
 }
 
@@ -117,7 +283,8 @@ func (w *Win) GetEventChan() chan interface{} {
 }
 
 // 当向已经关闭的Win发送信息时返回error, 一个良好设计的程序不用检查这个error
-func (w *Win) SendLineBack(s string) error {
+// 发送一行输出, 返回第一行的句柄
+func (w *Win) SendLineBack(s string) (LineID, error) {
 	return w.SendLineBackWithColor(GetDefaultSytleAttr(), s)
 }
 
@@ -125,62 +292,119 @@ func (w *Win) SendLineFront(s string) error {
 	return w.SendLineFrontWithColor(GetDefaultSytleAttr(), s)
 }
 
-// TODO 支持带有颜色的输出
-func (w *Win) SendLineBackWithColor(s ...interface{}) error {
+// 发送带颜色的一行输出, 参数为StyleAttr和string混合的片段, StyleAttr对之后的字符串生效
+// 字符串中的换行把输出拆成多行, 一次性添加, 样式延续到下一行
+// 返回第一行的句柄, 拆成多行时之后的行不能通过句柄访问, 需要单独修改的行应当分开发送
+func (w *Win) SendLineBackWithColor(s ...interface{}) (LineID, error) {
+	if w.isStopped {
+		return 0, errors.New("send to a closed window")
+	}
+
+	lines, ok := prepareOutputLines(w, s)
+	// 简单的检查, 参数是否规范
+	if !ok {
+		return 0, errors.New("invalid arguments")
+	}
+
+	data := newOutputLines(w, lines)
+	w.handler.PostEventWait(&sendLineBackWithColorEvent{when: time.Now(), data: data})
+	return data[0].id, nil
+}
+
+// 发送一行带有ANSI转义序列的输出, 颜色和样式按SGR解析, 其他转义序列被丢弃
+func (w *Win) SendANSILine(s string) (LineID, error) {
+	return w.SendLineBackWithColor(ParseANSI(s)...)
+}
+
+// 发送一行带有样式标记的输出, 如"[red::b]check![-] your move", 标记的格式见Markup
+func (w *Win) SendMarkup(s string) (LineID, error) {
+	return w.SendLineBackWithColor(Markup.Parse(s)...)
+}
+
+// 与SendLineBackWithColor相同, 但是添加到最前面, 拆成多行时保持原来的顺序
+func (w *Win) SendLineFrontWithColor(s ...interface{}) error {
 	if w.isStopped {
 		return errors.New("send to a closed window")
 	}
 
-	for k, v := range s {
-		attr, ok1 := v.(StyleAttr)
-		_, ok2 := v.(string)
-		// 简单的检查, 参数是否规范
-		if !ok1 && !ok2 {
-			return errors.New("invalid arguments")
-		}
-		if ok1 {
-			s[k] = styleAttr2TcellStyle(&attr)
-		}
+	lines, ok := prepareOutputLines(w, s)
+	if !ok {
+		return errors.New("invalid arguments")
 	}
 
-	w.handler.PostEventWait(&sendLineBackWithColorEvent{when: time.Now(), data: s})
+	w.handler.PostEventWait(&sendLineFrontWithColorEvent{when: time.Now(), data: newOutputLines(w, lines)})
 	return nil
 }
 
-func (w *Win) SendLineFrontWithColor(s ...interface{}) error {
+// 用新的内容替换句柄为id的行, 参数格式与SendLineBackWithColor相同
+// 新的内容拆成多行时, 第一行保留原来的句柄, 之后的行插入到它后面, 这一行已经被删除或者丢弃时什么也不做
+// 按句柄查找是O(1), 替换为一行时不移动其他行, 适合反复更新进度条之类的行, 拆成多行时插入的开销与之后的行数成正比
+func (w *Win) UpdateLine(id LineID, s ...interface{}) error {
 	if w.isStopped {
 		return errors.New("send to a closed window")
 	}
 
-	for k, v := range s {
-		attr, ok1 := v.(StyleAttr)
-		_, ok2 := v.(string)
-		// 简单的检查, 参数是否规范
-		if !ok1 && !ok2 {
-			return errors.New("invalid arguments")
-		}
-		if ok1 {
-			style := tcell.Style(0)
-			style = style.Background(tcell.Color(attr.Background))
-			style = style.Foreground(tcell.Color(attr.Foreground))
-			style = style.Blink(attr.Blink)
-			style = style.Bold(attr.Bold)
-			style = style.Dim(attr.Dim)
-			style = style.Italic(attr.Italic)
-			style = style.Reverse(attr.Reverse)
-			style = style.Underline(attr.Underline)
-			s[k] = style
-		}
+	lines, ok := prepareOutputLines(w, s)
+	if !ok {
+		return errors.New("invalid arguments")
+	}
+
+	w.handler.PostEventWait(&updateLineEvent{when: time.Now(), id: id, data: newOutputLines(w, lines)})
+	return nil
+}
+
+// 删除句柄为id的行, 这一行已经被删除或者丢弃时什么也不做
+// 需要移动之后的行, 开销与之后的行数成正比
+func (w *Win) DeleteLine(id LineID) error {
+	if w.isStopped {
+		return errors.New("send to a closed window")
 	}
 
-	w.handler.PostEventWait(&sendLineFrontWithColorEvent{when: time.Now(), data: s})
+	w.handler.PostEventWait(&deleteLineEvent{when: time.Now(), id: id})
 	return nil
 }
 
+// 在句柄为id的行之后插入输出, 参数格式与SendLineBackWithColor相同, 返回插入的第一行的句柄
+// 这一行已经被删除或者丢弃时不插入, 返回的句柄也不会对应任何行
+// 需要移动之后的行, 开销与之后的行数成正比
+func (w *Win) InsertAfter(id LineID, s ...interface{}) (LineID, error) {
+	if w.isStopped {
+		return 0, errors.New("send to a closed window")
+	}
+
+	lines, ok := prepareOutputLines(w, s)
+	if !ok {
+		return 0, errors.New("invalid arguments")
+	}
+
+	data := newOutputLines(w, lines)
+	w.handler.PostEventWait(&insertAfterEvent{when: time.Now(), id: id, data: data})
+	return data[0].id, nil
+}
+
+// 获取句柄为id的行的内容, 返回StyleAttr和string混合的片段, 这一行已经被删除或者丢弃时返回错误
+// 按句柄查找是O(1)
+func (w *Win) GetLine(id LineID) ([]interface{}, error) {
+	if w.isStopped {
+		return nil, errors.New("read from a closed window")
+	}
+
+	resp := make(chan []interface{}, 1)
+	w.handler.PostEventWait(&getLineEvent{when: time.Now(), id: id, resp: resp})
+	line, ok := <-resp
+	if !ok {
+		return nil, errors.New("line not found")
+	}
+	return line, nil
+}
+
 // 关闭窗口
 func (w *Win) Stop() {
 	w.handler.PostEventWait(&stopEvent{when: time.Now()})
 	<-w.waitStopChan
+	if w.terminalPasteMode {
+		setTerminalBracketedPaste(false)
+	}
 }
 
 // 追踪最新输出, 此时不允许上下移动, 但允许左右移动
@@ -188,7 +412,8 @@ func (w *Win) SetTrace(enable bool) {
 	w.handler.PostEventWait(&setTraceEvent{when: time.Now(), data: enable})
 }
 
-// 是否禁止输入, 当禁止输入时, 用户输入将被清空
+// 是否禁止输入, 默认禁止输入时用户输入将被清空, 可以通过Config.InputWhileBlocked允许阻塞期间继续输入
+// 解除阻塞时按顺序提交阻塞期间推迟或者排队的命令
 func (w *Win) SetBlockInput(ifBlock bool) {
 	w.handler.PostEventWait(&setBlockInputChangeEvent{when: time.Now(), data: ifBlock})
 }
@@ -245,7 +470,174 @@ func (w *Win) PopBackLine() {
 
 // 设置命令提示符的字符和颜色, 如果其中一个为nil, 那么就不修改此参数
 func (w *Win) SetPrompt(prompt *rune, promptStyle *StyleAttr) {
-	w.handler.PostEventWait(&setPromptEvent{when: time.Now(), dataRune: prompt, dataStyle: promptStyle})
+	var segs []interface{}
+	if prompt != nil {
+		segs = []interface{}{string(*prompt)}
+	}
+	w.handler.PostEventWait(&setPromptEvent{when: time.Now(), dataSegs: segs, dataStyle: promptStyle})
+}
+
+// 设置多字符的命令提示符, 使用当前命令提示符的颜色
+func (w *Win) SetPromptString(prompt string) error {
+	if w.isStopped {
+		return errors.New("send to a closed window")
+	}
+	w.handler.PostEventWait(&setPromptEvent{when: time.Now(), dataSegs: []interface{}{prompt}})
+	return nil
+}
+
+// 设置多字符带颜色的命令提示符, 参数格式与SendLineBackWithColor相同
+// 片段开头没有StyleAttr时使用当前命令提示符的颜色
+func (w *Win) SetPromptWithColor(s ...interface{}) error {
+	if w.isStopped {
+		return errors.New("send to a closed window")
+	}
+	if !checkSegments(s) {
+		return errors.New("invalid arguments")
+	}
+
+	segs := append([]interface{}{}, s...)
+	w.handler.PostEventWait(&setPromptEvent{when: time.Now(), dataSegs: segs})
+	return nil
+}
+
+// 替换输入行的内容, 光标移动到末尾, 单行模式下换行将变为空格, 超出最大长度的部分被丢弃
+func (w *Win) SetInput(s string) error {
+	if w.isStopped {
+		return errors.New("send to a closed window")
+	}
+	w.handler.PostEventWait(&setInputEvent{when: time.Now(), data: s})
+	return nil
+}
+
+// 在光标处插入内容, 光标移动到插入的内容之后
+func (w *Win) InsertAtCursor(s string) error {
+	if w.isStopped {
+		return errors.New("send to a closed window")
+	}
+	w.handler.PostEventWait(&insertInputEvent{when: time.Now(), data: s})
+	return nil
+}
+
+// 获取输入行当前的内容
+func (w *Win) GetInput() (string, error) {
+	if w.isStopped {
+		return "", errors.New("read from a closed window")
+	}
+	c := make(chan string, 1)
+	w.handler.PostEventWait(&getInputEvent{when: time.Now(), resp: c})
+	return <-c, nil
+}
+
+// 设置补全器, 为nil时关闭补全, Tab恢复为Ctrl+I事件
+func (w *Win) SetCompleter(c Completer) {
+	w.handler.PostEventWait(&setCompleterEvent{when: time.Now(), data: c})
+}
+
+// 设置输入高亮, 为nil时取消高亮
+func (w *Win) SetHighlighter(h Highlighter) {
+	w.handler.PostEventWait(&setHighlighterEvent{when: time.Now(), data: h})
+}
+
+// 设置输入行右侧的提示符, 参数格式与SendLineBackWithColor相同, 不传参数时清除
+// 片段开头没有StyleAttr时使用命令提示符的颜色
+func (w *Win) SetRightPrompt(s ...interface{}) error {
+	if w.isStopped {
+		return errors.New("send to a closed window")
+	}
+	if !checkSegments(s) {
+		return errors.New("invalid arguments")
+	}
+
+	segs := append([]interface{}{}, s...)
+	w.handler.PostEventWait(&setRightPromptEvent{when: time.Now(), data: segs})
+	return nil
+}
+
+// 设置输入被阻塞时显示的消息, 为空时不显示
+func (w *Win) SetBusyMessage(msg string) {
+	w.handler.PostEventWait(&setBusyMessageEvent{when: time.Now(), data: msg})
+}
+
+// 设置动态的命令提示符, 为nil时恢复使用SetPrompt等设置的命令提示符
+func (w *Win) SetPromptFunc(f func() []interface{}) {
+	w.handler.PostEventWait(&setPromptFuncEvent{when: time.Now(), data: f})
+}
+
+// 设置输入建议的来源, 为nil时只使用历史命令(如果启用了SuggestFromHistory)
+func (w *Win) SetSuggester(sg Suggester) {
+	w.handler.PostEventWait(&setSuggesterEvent{when: time.Now(), data: sg})
+}
+
+// 开启掩码输入, 输入的字符显示为mask, mask为0时不显示任何字符, 掩码输入的命令不会记入历史命令
+func (w *Win) SetInputMask(mask rune) {
+	w.handler.PostEventWait(&setInputMaskEvent{when: time.Now(), enable: true, mask: mask})
+}
+
+// 关闭掩码输入
+func (w *Win) ClearInputMask() {
+	w.handler.PostEventWait(&setInputMaskEvent{when: time.Now(), enable: false})
+}
+
+// 临时使用prompt作为命令提示符读取一行掩码输入, 如ReadSecret("password:"), 输入显示为'*'
+// 读取到的内容直接返回, 不发送到GetCmdChan, 也不记入历史命令
+// 阻塞直到用户回车, 之后恢复原来的命令提示符, 输入和阻塞状态
+func (w *Win) ReadSecret(prompt string) (string, error) {
+	r, err := w.read(&readLineEvent{prompt: []interface{}{prompt}, masked: true, mask: '*'})
+	return r.text, err
+}
+
+// 在输入行显示问题, 读取一行回答, 回答直接返回, 不发送到GetCmdChan, 也不记入历史命令
+// 阻塞直到用户回车, 之后恢复原来的命令提示符, 输入和阻塞状态
+func (w *Win) Ask(question string) (string, error) {
+	r, err := w.read(&readLineEvent{prompt: []interface{}{"? " + question}})
+	return r.text, err
+}
+
+// 在输入行显示问题, 读取y/yes或者n/no, 不区分大小写, 其他回答会重新询问
+// 阻塞直到用户回答, 之后恢复原来的命令提示符, 输入和阻塞状态
+func (w *Win) Confirm(question string) (bool, error) {
+	for {
+		r, err := w.read(&readLineEvent{prompt: []interface{}{"? " + question + " (y/n)"}})
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(strings.TrimSpace(r.text)) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// 在输入行上方列出选项, 上下键或Tab移动选中项, 回车选择, 也可以输入选项的序号后回车
+// 返回选择的选项的下标, 阻塞直到用户选择, 之后恢复原来的命令提示符, 输入和阻塞状态
+func (w *Win) Select(title string, options []string) (int, error) {
+	if len(options) == 0 {
+		return -1, errors.New("no options to select")
+	}
+	r, err := w.read(&readLineEvent{prompt: []interface{}{"? " + title}, options: append([]string(nil), options...)})
+	if err != nil {
+		return -1, err
+	}
+	return r.index, nil
+}
+
+func (w *Win) read(event *readLineEvent) (readResult, error) {
+	if w.isStopped {
+		return readResult{index: -1}, errors.New("read from a closed window")
+	}
+
+	c := make(chan readResult, 1)
+	event.when = time.Now()
+	event.resp = c
+	w.handler.PostEventWait(event)
+	r, ok := <-c
+	if !ok {
+		return readResult{index: -1}, errors.New("window closed or another read in progress")
+	}
+	return r, nil
 }
 
 func (w *Win) GetWindowSize() (height int, width int) {
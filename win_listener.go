@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"github.com/gdamore/tcell"
-	"github.com/mattn/go-runewidth"
 )
 
 func doListen(w *Win) {
@@ -14,228 +13,13 @@ func doListen(w *Win) {
 
 		switch event := ev.(type) {
 		case *tcell.EventKey:
-			// 特殊键特殊处理
-			// 回车
-			switch event.Key() {
-			case tcell.KeyEnter:
-				if w.blockedNow {
-					continue
-				}
-
-				// 清空最后一行
-				for i, j := 0, w.promptWidth+1; i < w.curwidth; i, j = i+1, j+1 {
-					s.SetContent(j, w.curmaxY, ' ', nil, tcell.StyleDefault)
-				}
-				s.ShowCursor(w.promptWidth+1, w.curmaxY)
-				s.Show()
-				stringCmd := string(w.input)
-				go func() {
-					w.cmdC <- stringCmd
-				}()
-				w.input = nil
-				w.curwidth = 0
-				if w.blockInputAfterEnter {
-					w.blockedNow = true
-				}
-				// CTRL+H: windows: KeyBackSpace
-				// ETB: linux: CTRL BACKSPACE
-				// BACKSPACE2 :linux: BACKSPACE windows: CTRL BACKSPACE
-			case tcell.KeyCtrlH, tcell.KeyBackspace2, tcell.KeyETB:
-				if w.blockedNow {
-					continue
-				}
-
-				if w.curwidth == 0 {
-					break
-				}
-
-				// 更新数据结构
-				w.curwidth -= runewidth.RuneWidth(w.input[len(w.input)-1])
-				w.input = w.input[0 : len(w.input)-1]
-				reDraw(w, false)
-			case tcell.KeyUp:
-				if w.trace {
-					if w.eventMask&EventMaskKeyUpWhenTrace == EventMaskKeyUpWhenTrace {
-						go func() {
-							w.specialEventC <- &EventTypeUpWhenTrace{When: time.Now()}
-						}()
-					}
-					continue
-				}
-				if w.loff == 0 {
-					if w.eventMask&EventMaskTryToMoveUpper == EventMaskTryToMoveUpper {
-						go func() {
-							w.specialEventC <- &EventTryToGetUpper{When: time.Now()}
-						}()
-					}
-					continue
-				}
-
-				if w.eventMask&EventMaskKeyUp == EventMaskKeyUp {
-					go func() {
-						w.specialEventC <- &EventMoveUp{When: time.Now()}
-					}()
-				}
-				w.loff -= 1
-				reDraw(w, false)
-			case tcell.KeyDown:
-				maxloff, _ := getMaxLoffAndOutputN(w.curmaxY, len(w.lines))
-				if w.trace {
-					if w.eventMask&EventMaskKeyDownWhenTrace == EventMaskKeyDownWhenTrace {
-						go func() {
-							w.specialEventC <- &EventTypeDownWhenTrace{When: time.Now()}
-						}()
-					}
-					continue
-				}
-				if w.loff == maxloff {
-					if w.eventMask&EventMaskTryToMoveLower == EventMaskTryToMoveLower {
-						go func() {
-							w.specialEventC <- &EventTryToGetLower{When: time.Now()}
-						}()
-					}
-					continue
-				}
-
-				if w.eventMask&EventMaskKeyDown == EventMaskKeyDown {
-					go func() {
-						w.specialEventC <- &EventMoveDown{When: time.Now()}
-					}()
-				}
-				w.loff += 1
-				reDraw(w, false)
-			case tcell.KeyRight:
-				if w.curmaxX+1 > maxwidthfrom(w.lines, w.coff+1) {
-					continue
-				}
-				w.coff++
-				reDraw(w, false)
-			case tcell.KeyLeft:
-				if w.coff == 0 {
-					continue
-				}
-				w.coff--
-				reDraw(w, false)
-			case tcell.KeyCtrlSpace:
-				if w.eventMask&EventMaskKeyCtrlSpace == EventMaskKeyCtrlSpace {
-					w.specialEventC <- &EventKeyCtrlSpace{When: time.Now()}
-				}
-			case tcell.KeyCtrlA:
-				if w.eventMask&EventMaskKeyCtrlA == EventMaskKeyCtrlA {
-					w.specialEventC <- &EventKeyCtrlA{When: time.Now()}
-				}
-			case tcell.KeyCtrlB:
-				if w.eventMask&EventMaskKeyCtrlB == EventMaskKeyCtrlB {
-					w.specialEventC <- &EventKeyCtrlB{When: time.Now()}
-				}
-			case tcell.KeyCtrlC:
-				if w.eventMask&EventMaskKeyCtrlC == EventMaskKeyCtrlC {
-					w.specialEventC <- &EventKeyCtrlC{When: time.Now()}
-				}
-			case tcell.KeyCtrlD:
-				if w.eventMask&EventMaskKeyCtrlD == EventMaskKeyCtrlD {
-					w.specialEventC <- &EventKeyCtrlD{When: time.Now()}
-				}
-			case tcell.KeyCtrlE:
-				if w.eventMask&EventMaskKeyCtrlE == EventMaskKeyCtrlE {
-					w.specialEventC <- &EventKeyCtrlE{When: time.Now()}
-				}
-			case tcell.KeyCtrlF:
-				if w.eventMask&EventMaskKeyCtrlF == EventMaskKeyCtrlF {
-					w.specialEventC <- &EventKeyCtrlF{When: time.Now()}
-				}
-			case tcell.KeyCtrlG:
-				if w.eventMask&EventMaskKeyCtrlG == EventMaskKeyCtrlG {
-					w.specialEventC <- &EventKeyCtrlG{When: time.Now()}
-				}
-			case tcell.KeyCtrlI:
-				if w.eventMask&EventMaskKeyCtrlI == EventMaskKeyCtrlI {
-					w.specialEventC <- &EventKeyCtrlI{When: time.Now()}
-				}
-			case tcell.KeyCtrlJ:
-				if w.eventMask&EventMaskKeyCtrlJ == EventMaskKeyCtrlJ {
-					w.specialEventC <- &EventKeyCtrlJ{When: time.Now()}
-				}
-			case tcell.KeyCtrlK:
-				if w.eventMask&EventMaskKeyCtrlK == EventMaskKeyCtrlK {
-					w.specialEventC <- &EventKeyCtrlK{When: time.Now()}
-				}
-			case tcell.KeyCtrlL:
-				if w.eventMask&EventMaskKeyCtrlL == EventMaskKeyCtrlL {
-					w.specialEventC <- &EventKeyCtrlL{When: time.Now()}
-				}
-			case tcell.KeyCtrlN:
-				if w.eventMask&EventMaskKeyCtrlN == EventMaskKeyCtrlN {
-					w.specialEventC <- &EventKeyCtrlN{When: time.Now()}
-				}
-			case tcell.KeyCtrlO:
-				if w.eventMask&EventMaskKeyCtrlO == EventMaskKeyCtrlO {
-					w.specialEventC <- &EventKeyCtrlO{When: time.Now()}
-				}
-			case tcell.KeyCtrlP:
-				if w.eventMask&EventMaskKeyCtrlP == EventMaskKeyCtrlP {
-					w.specialEventC <- &EventKeyCtrlP{When: time.Now()}
-				}
-			case tcell.KeyCtrlQ:
-				if w.eventMask&EventMaskKeyCtrlQ == EventMaskKeyCtrlQ {
-					w.specialEventC <- &EventKeyCtrlQ{When: time.Now()}
-				}
-			case tcell.KeyCtrlR:
-				if w.eventMask&EventMaskKeyCtrlR == EventMaskKeyCtrlR {
-					w.specialEventC <- &EventKeyCtrlR{When: time.Now()}
-				}
-			case tcell.KeyCtrlS:
-				if w.eventMask&EventMaskKeyCtrlS == EventMaskKeyCtrlS {
-					w.specialEventC <- &EventKeyCtrlS{When: time.Now()}
-				}
-			case tcell.KeyCtrlT:
-				if w.eventMask&EventMaskKeyCtrlT == EventMaskKeyCtrlT {
-					w.specialEventC <- &EventKeyCtrlT{When: time.Now()}
-				}
-			case tcell.KeyCtrlU:
-				if w.eventMask&EventMaskKeyCtrlU == EventMaskKeyCtrlU {
-					w.specialEventC <- &EventKeyCtrlU{When: time.Now()}
-				}
-			case tcell.KeyCtrlV:
-				if w.eventMask&EventMaskKeyCtrlV == EventMaskKeyCtrlV {
-					w.specialEventC <- &EventKeyCtrlV{When: time.Now()}
-				}
-			// case tcell.KeyCtrlW:
-			// 	if w.eventMask&EventMaskKeyCtrlW == EventMaskKeyCtrlW {
-			// 		w.specialEventC <- &EventKeyCtrlW{When: time.Now()}
-			// 	}
-			case tcell.KeyCtrlX:
-				if w.eventMask&EventMaskKeyCtrlX == EventMaskKeyCtrlX {
-					w.specialEventC <- &EventKeyCtrlX{When: time.Now()}
-				}
-			case tcell.KeyCtrlY:
-				if w.eventMask&EventMaskKeyCtrlY == EventMaskKeyCtrlY {
-					w.specialEventC <- &EventKeyCtrlY{When: time.Now()}
-				}
-			case tcell.KeyCtrlZ:
-				if w.eventMask&EventMaskKeyCtrlZ == EventMaskKeyCtrlZ {
-					w.specialEventC <- &EventKeyCtrlZ{When: time.Now()}
-				}
-			}
-
-			// 忽略非普通rune字符
-			if event.Key() != tcell.KeyRune {
+			if w.bracketedPaste && handlePasteKey(w, event) {
 				continue
 			}
-
-			if w.blockedNow {
-				continue
-			}
-			c := event.Rune()
-			cWidth := runewidth.RuneWidth(c)
-			if w.curmaxX+1-(w.promptWidth+1)-w.curwidth > cWidth {
-				s.SetContent(w.curwidth+w.promptWidth+1, w.curmaxY, c, nil, tcell.StyleDefault)
-				w.curwidth += cWidth
-				s.ShowCursor(w.curwidth+w.promptWidth+1, w.curmaxY)
-				s.Show()
-				w.input = append(w.input, c)
-			} else {
-				s.Beep()
+			handleKey(w, event)
+		case *pasteTimeoutEvent:
+			if event.seq == w.paste.seq {
+				flushPaste(w)
 			}
 		case *tcell.EventResize:
 			x, y := s.Size()
@@ -250,33 +34,58 @@ func doListen(w *Win) {
 			}
 		case *stopEvent:
 			w.isStopped = true
+			close(w.tickStop)
+			if w.paste.timer != nil {
+				w.paste.timer.Stop()
+			}
+			if w.reader != nil {
+				close(w.reader.resp)
+				w.reader = nil
+			}
 			w.handler.Fini()
 			w.waitStopChan <- struct{}{}
 			return
 		case *setBlockInputChangeEvent:
+			// 临时读取期间不打断读取, 读取完成后再生效
+			if w.reader != nil {
+				w.reader.blockedNow = event.data
+				continue
+			}
 			w.blockedNow = event.data
-			w.input = nil
-			w.curwidth = 0
-			// 开始先画一个命令提示符出来
-			for i := 0; i < w.curmaxX; i++ {
-				w.handler.SetContent(i, w.curmaxY, ' ', nil, tcell.StyleDefault)
-			}
-			s.SetContent(0, w.curmaxY, w.prompt, nil, styleAttr2TcellStyle(&w.promptStyle))
-			s.ShowCursor(w.promptWidth+1, w.curmaxY)
-			s.Show()
+
+			// 阻塞期间允许输入时保留输入, 解除阻塞时提交推迟的命令
+			if w.inputWhileBlocked != BlockedInputDiscard {
+				if !w.blockedNow {
+					releaseHeldInput(w)
+				} else {
+					showInput(w)
+				}
+				continue
+			}
+			inputReset(w)
+			if w.history != nil {
+				w.history.reset()
+			}
+			w.search = nil
+			if w.completion != nil {
+				w.completion = nil
+				reDraw(w, false)
+				continue
+			}
+			showInput(w)
 		case *clearEvent:
-			w.lines = nil
+			w.lines.clear()
 			w.coff = 0
 			w.loff = 0
+			w.woff = 0
 			reDraw(w, false)
 		case *gotoBottomEvent:
 			w.trace = false
-			maxloff, _ := getMaxLoffAndOutputN(w.curmaxY, len(w.lines))
-			w.loff = maxloff
+			w.loff, w.woff = maxOutputOff(w)
 			reDraw(w, false)
 		case *gotoTopEvent:
 			w.trace = false
-			w.loff = 0
+			w.loff, w.woff = 0, 0
 			reDraw(w, false)
 		case *gotoLeftEvent:
 			if w.coff != 0 {
@@ -290,105 +99,173 @@ func doListen(w *Win) {
 			w.blockInputAfterEnter = event.data
 		case *gotoLineEvent:
 			w.trace = false
-			if event.data-1 == w.loff {
+			// 折行时仍然按逻辑行定位, 跳到这一行的第一个屏幕行
+			if event.data-1 == w.loff && w.woff == 0 {
 				continue
 			}
-			maxloff, _ := getMaxLoffAndOutputN(w.curmaxY, len(w.lines))
+			ml, mr := maxOutputOff(w)
 			if event.data <= 0 {
-				w.loff = 0
-			} else if event.data >= maxloff+1 {
-				w.loff = maxloff
+				w.loff, w.woff = 0, 0
+			} else if outputOffLess(ml, mr, event.data-1, 0) {
+				w.loff, w.woff = ml, mr
 			} else {
-				w.loff = event.data - 1
+				w.loff, w.woff = event.data-1, 0
 			}
 			reDraw(w, false)
 		case *gotoNextLineEvent:
 			w.trace = false
-			maxloff, _ := getMaxLoffAndOutputN(w.curmaxY, len(w.lines))
-			if w.loff == maxloff {
+			if !scrollOutput(w, 1) {
 				continue
 			}
-			w.loff++
 			reDraw(w, false)
 		case *gotoPreviousLineEvent:
 			w.trace = false
-			if w.loff == 0 {
+			if !scrollOutput(w, -1) {
 				continue
 			}
-			w.loff--
 			reDraw(w, false)
 		case *sendLineFrontWithColorEvent:
-			newLines := make([]([]interface{}), len(w.lines)+1, (len(w.lines)+1)*2)
-			newLines[0] = event.data
-			for i := 1; i <= len(w.lines); i++ {
-				newLines[i] = w.lines[i-1]
+			n := len(event.data)
+			for i := n - 1; i >= 0; i-- {
+				w.lines.pushFront(event.data[i])
 			}
-			w.lines = newLines
 
+			// 追踪最新输出时从前面添加不影响显示, 除非丢弃了最后面的行
 			if w.trace {
+				if evictLines(w, true) {
+					reDraw(w, false)
+				}
 				continue
 			}
 
-			maxloff, _ := getMaxLoffAndOutputN(w.curmaxY, len(w.lines))
-			if w.loff == maxloff {
+			if outputAtMax(w) {
+				evictLines(w, true)
 				reDraw(w, false)
 				continue
 			}
 
 			// TODO 是否合适?
-			w.loff++
+			w.loff += n
+			evictLines(w, true)
 			reDraw(w, false)
 		case *sendLineBackWithColorEvent:
-			w.lines = append(w.lines, event.data)
+			for _, line := range event.data {
+				w.lines.pushBack(line)
+			}
+			evictLines(w, false)
 			reDraw(w, false)
 		case *popBackLineEvent:
-			if len(w.lines) == 0 {
+			if w.lines.len() == 0 {
 				continue
 			}
-			w.lines = w.lines[:len(w.lines)-1]
-			maxloff, _ := getMaxLoffAndOutputN(w.curmaxY, len(w.lines))
-			if w.loff > maxloff {
-				w.loff = maxloff
-			}
+			w.lines.popBack()
+			clampOutputOff(w)
 			reDraw(w, false)
 		case *popFrontLineEvent:
-			if len(w.lines) == 0 {
+			if w.lines.len() == 0 {
 				continue
 			}
-			w.lines = w.lines[1:]
+			w.lines.popFront()
 			if w.trace {
 				continue
 			}
 			if w.loff >= 1 {
 				w.loff--
+			} else {
+				w.woff = 0
 			}
 			reDraw(w, false)
 		case *setPromptEvent:
-			if event.dataRune != nil {
-				w.prompt = *event.dataRune
+			// 临时读取期间命令提示符在读取完成后再生效
+			if event.dataSegs != nil && w.reader != nil {
+				w.reader.promptSegs = event.dataSegs
+			} else if event.dataSegs != nil {
+				w.promptSegs = event.dataSegs
 			}
 			if event.dataStyle != nil {
 				w.promptStyle = *event.dataStyle
 			}
 
-			// 清空最后一行, 因为可能会发生命令提示符的宽度改变的情况
-			// 这里需要一次性更新最后一行
-			for i, j := 0, w.promptWidth+1; i < w.curwidth; i, j = i+1, j+1 {
-				s.SetContent(j, w.curmaxY, ' ', nil, tcell.StyleDefault)
+			// 命令提示符的宽度可能改变, 需要一次性重画最后一行
+			updatePrompt(w)
+			updateRightPrompt(w)
+			showInput(w)
+		case *setRightPromptEvent:
+			w.rightPromptSegs = event.data
+			updateRightPrompt(w)
+			showInput(w)
+		case *setPromptFuncEvent:
+			w.promptFunc = event.data
+			updatePrompt(w)
+			showInput(w)
+		case *promptTickEvent:
+			if w.promptFunc != nil && w.reader == nil {
+				showInput(w)
+			}
+		case *busyTickEvent:
+			if showBusy(w) {
+				w.busyFrame++
+				showInput(w)
+			}
+		case *setBusyMessageEvent:
+			w.busy.Message = event.data
+			showInput(w)
+		case *setInputMaskEvent:
+			w.masked = event.enable
+			w.inputMask = event.mask
+			if w.masked {
+				w.search = nil
+				if w.completion != nil {
+					w.completion = nil
+					reDraw(w, false)
+					continue
+				}
+			}
+			showInput(w)
+		case *readLineEvent:
+			beginRead(w, event)
+		case *setHighlighterEvent:
+			w.highlighter = event.data
+			w.highlight = nil
+			showInput(w)
+		case *setSuggesterEvent:
+			w.suggester = event.data
+			w.suggestion = nil
+			showInput(w)
+		case *setInputEvent:
+			endSearchAndCompletion(w)
+			rs := sanitizeInput(w, event.data)
+			if w.maxInputLength > 0 && len(rs) > w.maxInputLength {
+				rs = rs[:w.maxInputLength]
+			}
+			pushUndo(w, editOther)
+			inputSet(w, rs)
+			endEdit(w)
+			showInput(w)
+		case *insertInputEvent:
+			endSearchAndCompletion(w)
+			inputInsert(w, sanitizeInput(w, event.data))
+			showInput(w)
+		case *updateLineEvent:
+			updateLine(w, event.id, event.data)
+		case *deleteLineEvent:
+			deleteLine(w, event.id)
+		case *insertAfterEvent:
+			insertAfterLine(w, event.id, event.data)
+		case *getLineEvent:
+			if i := w.lines.find(event.id); i >= 0 {
+				event.resp <- outputLineSegments(w.lines.at(i))
+			} else {
+				close(event.resp)
+			}
+		case *getInputEvent:
+			event.resp <- string(w.input)
+		case *setCompleterEvent:
+			w.completer = event.data
+			if w.completion != nil {
+				w.completion = nil
+				reDraw(w, false)
 			}
-
-			// 画新的命令提示符
-			s.SetContent(0, w.curmaxY, w.prompt, nil, styleAttr2TcellStyle(&w.promptStyle))
-			w.promptWidth = runewidth.RuneWidth(w.prompt)
-
-			// 现在写已有的消息
-			offset := w.promptWidth + 1
-			for i := 0; i < len(w.input); i++ {
-				s.SetContent(offset, w.curmaxY, w.input[i], nil, tcell.StyleDefault)
-				offset += runewidth.RuneWidth(w.input[i])
-			}
-			s.ShowCursor(offset, w.curmaxY)
-			s.Show()
 		case *getWindowSizeEvent:
 			resp := new(getWindowSizeEventResp)
 			resp.height = w.curmaxY + 1
@@ -397,3 +274,366 @@ func doListen(w *Win) {
 		}
 	}
 }
+
+// 处理一次按键
+func handleKey(w *Win, event *tcell.EventKey) {
+	s := w.handler
+
+	// 除了Tab以外的按键都会结束正在进行的补全
+	if w.completion != nil && event.Key() != tcell.KeyTab && event.Key() != tcell.KeyBacktab {
+		w.completion = nil
+		reDraw(w, false)
+	}
+
+	// 搜索历史命令时按键优先交给搜索处理
+	if w.search != nil && handleHistorySearchKey(w, event) {
+		return
+	}
+
+	if w.viMode && handleViKey(w, event) {
+		return
+	}
+
+	// 选择时上下键用于移动选中项
+	if handleSelectKey(w, event) {
+		return
+	}
+
+	// 特殊键特殊处理
+	// 回车
+	switch event.Key() {
+	case tcell.KeyEnter:
+		if inputBlocked(w) {
+			return
+		}
+
+		// 多行模式下回车插入换行, 除非使用Alt+回车提交, 临时读取时回车总是提交
+		if w.multiLine && w.reader == nil && !(w.multiLineSubmitKey == SubmitKeyAltEnter && event.Modifiers()&tcell.ModAlt != 0) {
+			if inputInsert(w, []rune{'\n'}) {
+				showInput(w)
+			} else {
+				s.Beep()
+			}
+			return
+		}
+
+		submitInput(w)
+		// CTRL+H: windows: KeyBackSpace
+		// BACKSPACE2 :linux: BACKSPACE windows: CTRL BACKSPACE
+		// ETB(CTRL+W): linux: CTRL BACKSPACE, 见下面的KeyCtrlW
+	case tcell.KeyCtrlH, tcell.KeyBackspace2:
+		if inputBlocked(w) {
+			return
+		}
+
+		if !inputDeleteBackward(w) {
+			break
+		}
+		showInput(w)
+	case tcell.KeyDelete:
+		if inputBlocked(w) {
+			return
+		}
+
+		if !inputDeleteForward(w) {
+			break
+		}
+		showInput(w)
+	case tcell.KeyHome:
+		if inputBlocked(w) {
+			return
+		}
+
+		// 多行模式下跳到当前行的行首
+		lines := inputLines(w)
+		w.cursor = lines[cursorLine(lines, w.cursor)].from
+		showInput(w)
+	case tcell.KeyEnd:
+		if inputBlocked(w) {
+			return
+		}
+
+		// 光标已经在末尾时接受建议
+		if w.cursor == len(w.input) && acceptSuggestion(w) {
+			showInput(w)
+			return
+		}
+
+		lines := inputLines(w)
+		w.cursor = lines[cursorLine(lines, w.cursor)].to
+		showInput(w)
+	case tcell.KeyUp:
+		if w.trace {
+			if w.eventMask&EventMaskKeyUpWhenTrace == EventMaskKeyUpWhenTrace {
+				go func() {
+					w.specialEventC <- &EventTypeUpWhenTrace{When: time.Now()}
+				}()
+			}
+			return
+		}
+		if w.loff == 0 && w.woff == 0 {
+			if w.eventMask&EventMaskTryToMoveUpper == EventMaskTryToMoveUpper {
+				go func() {
+					w.specialEventC <- &EventTryToGetUpper{When: time.Now()}
+				}()
+			}
+			return
+		}
+
+		if w.eventMask&EventMaskKeyUp == EventMaskKeyUp {
+			go func() {
+				w.specialEventC <- &EventMoveUp{When: time.Now()}
+			}()
+		}
+		scrollOutput(w, -1)
+		reDraw(w, false)
+	case tcell.KeyDown:
+		if w.trace {
+			if w.eventMask&EventMaskKeyDownWhenTrace == EventMaskKeyDownWhenTrace {
+				go func() {
+					w.specialEventC <- &EventTypeDownWhenTrace{When: time.Now()}
+				}()
+			}
+			return
+		}
+		if outputAtMax(w) {
+			if w.eventMask&EventMaskTryToMoveLower == EventMaskTryToMoveLower {
+				go func() {
+					w.specialEventC <- &EventTryToGetLower{When: time.Now()}
+				}()
+			}
+			return
+		}
+
+		if w.eventMask&EventMaskKeyDown == EventMaskKeyDown {
+			go func() {
+				w.specialEventC <- &EventMoveDown{When: time.Now()}
+			}()
+		}
+		scrollOutput(w, 1)
+		reDraw(w, false)
+	case tcell.KeyRight:
+		// 默认左右键移动输入光标, Shift+左右键横向滚动输出, ArrowKeysScrollOutput时反过来
+		if (event.Modifiers()&tcell.ModShift != 0) != w.arrowKeysScrollOutput {
+			// 折行时不需要横向滚动
+			if w.wrap != WrapNone || w.curmaxX+1 > maxwidthfrom(&w.lines, w.coff+1) {
+				return
+			}
+			w.coff++
+			reDraw(w, false)
+			return
+		}
+
+		if inputBlocked(w) {
+			return
+		}
+		// 光标已经在末尾时接受建议
+		if !inputMoveCursor(w, 1) && !acceptSuggestion(w) {
+			return
+		}
+		showInput(w)
+	case tcell.KeyLeft:
+		if (event.Modifiers()&tcell.ModShift != 0) != w.arrowKeysScrollOutput {
+			if w.coff == 0 {
+				return
+			}
+			w.coff--
+			reDraw(w, false)
+			return
+		}
+
+		if inputBlocked(w) || !inputMoveCursor(w, -1) {
+			return
+		}
+		showInput(w)
+	case tcell.KeyCtrlSpace:
+		if w.eventMask&EventMaskKeyCtrlSpace == EventMaskKeyCtrlSpace {
+			w.specialEventC <- &EventKeyCtrlSpace{When: time.Now()}
+		}
+	case tcell.KeyCtrlA:
+		if w.eventMask&EventMaskKeyCtrlA == EventMaskKeyCtrlA {
+			w.specialEventC <- &EventKeyCtrlA{When: time.Now()}
+		}
+	case tcell.KeyCtrlB:
+		if w.eventMask&EventMaskKeyCtrlB == EventMaskKeyCtrlB {
+			w.specialEventC <- &EventKeyCtrlB{When: time.Now()}
+		}
+	case tcell.KeyCtrlC:
+		if w.eventMask&EventMaskKeyCtrlC == EventMaskKeyCtrlC {
+			w.specialEventC <- &EventKeyCtrlC{When: time.Now()}
+		}
+	case tcell.KeyCtrlD:
+		// 多行模式下Ctrl+D可以用于提交输入
+		if w.multiLine && w.multiLineSubmitKey == SubmitKeyCtrlD {
+			if inputBlocked(w) {
+				return
+			}
+			submitInput(w)
+			return
+		}
+		if w.eventMask&EventMaskKeyCtrlD == EventMaskKeyCtrlD {
+			w.specialEventC <- &EventKeyCtrlD{When: time.Now()}
+		}
+	case tcell.KeyCtrlE:
+		if w.eventMask&EventMaskKeyCtrlE == EventMaskKeyCtrlE {
+			w.specialEventC <- &EventKeyCtrlE{When: time.Now()}
+		}
+	case tcell.KeyCtrlF:
+		if w.eventMask&EventMaskKeyCtrlF == EventMaskKeyCtrlF {
+			w.specialEventC <- &EventKeyCtrlF{When: time.Now()}
+		}
+	case tcell.KeyCtrlG:
+		if w.eventMask&EventMaskKeyCtrlG == EventMaskKeyCtrlG {
+			w.specialEventC <- &EventKeyCtrlG{When: time.Now()}
+		}
+	case tcell.KeyCtrlI:
+		// 设置了补全器时, Tab用于补全
+		if w.completer != nil && !w.masked {
+			if inputBlocked(w) {
+				return
+			}
+			completeNext(w, 1)
+			reDraw(w, false)
+			return
+		}
+		if w.eventMask&EventMaskKeyCtrlI == EventMaskKeyCtrlI {
+			w.specialEventC <- &EventKeyCtrlI{When: time.Now()}
+		}
+	case tcell.KeyBacktab:
+		if w.completer == nil || w.masked || inputBlocked(w) {
+			return
+		}
+		completeNext(w, -1)
+		reDraw(w, false)
+	case tcell.KeyCtrlJ:
+		if w.eventMask&EventMaskKeyCtrlJ == EventMaskKeyCtrlJ {
+			w.specialEventC <- &EventKeyCtrlJ{When: time.Now()}
+		}
+	case tcell.KeyCtrlK:
+		// 没有注册这个事件时, 使用内置的编辑动作: 剪切到行尾
+		if w.eventMask&EventMaskKeyCtrlK == EventMaskKeyCtrlK {
+			w.specialEventC <- &EventKeyCtrlK{When: time.Now()}
+		} else if !inputBlocked(w) && inputKillToEnd(w) {
+			showInput(w)
+		}
+	case tcell.KeyCtrlL:
+		if w.eventMask&EventMaskKeyCtrlL == EventMaskKeyCtrlL {
+			w.specialEventC <- &EventKeyCtrlL{When: time.Now()}
+		}
+	case tcell.KeyCtrlN:
+		// 注册了这个事件时产生事件, 否则启用历史命令时, Ctrl+N用于前往下一条命令
+		if w.eventMask&EventMaskKeyCtrlN == EventMaskKeyCtrlN {
+			w.specialEventC <- &EventKeyCtrlN{When: time.Now()}
+		} else if w.history != nil && !w.masked && !inputBlocked(w) {
+			if rs, ok := w.history.next(); ok {
+				pushUndo(w, editOther)
+				inputSet(w, sanitizeInput(w, string(rs)))
+				showInput(w)
+			}
+		}
+	case tcell.KeyCtrlO:
+		if w.eventMask&EventMaskKeyCtrlO == EventMaskKeyCtrlO {
+			w.specialEventC <- &EventKeyCtrlO{When: time.Now()}
+		}
+	case tcell.KeyCtrlP:
+		// 注册了这个事件时产生事件, 否则启用历史命令时, Ctrl+P用于回到上一条命令
+		if w.eventMask&EventMaskKeyCtrlP == EventMaskKeyCtrlP {
+			w.specialEventC <- &EventKeyCtrlP{When: time.Now()}
+		} else if w.history != nil && !w.masked && !inputBlocked(w) {
+			if rs, ok := w.history.prev(w.input); ok {
+				pushUndo(w, editOther)
+				inputSet(w, sanitizeInput(w, string(rs)))
+				showInput(w)
+			}
+		}
+	case tcell.KeyCtrlQ:
+		if w.eventMask&EventMaskKeyCtrlQ == EventMaskKeyCtrlQ {
+			w.specialEventC <- &EventKeyCtrlQ{When: time.Now()}
+		}
+	case tcell.KeyCtrlR:
+		// 注册了这个事件时产生事件, 否则启用反向搜索时, Ctrl+R用于搜索历史命令
+		if w.eventMask&EventMaskKeyCtrlR == EventMaskKeyCtrlR {
+			w.specialEventC <- &EventKeyCtrlR{When: time.Now()}
+		} else if w.history != nil && w.history.reverseSearch && !w.masked && !inputBlocked(w) {
+			startHistorySearch(w)
+			showInput(w)
+		}
+	case tcell.KeyCtrlS:
+		if w.eventMask&EventMaskKeyCtrlS == EventMaskKeyCtrlS {
+			w.specialEventC <- &EventKeyCtrlS{When: time.Now()}
+		}
+	case tcell.KeyCtrlT:
+		if w.eventMask&EventMaskKeyCtrlT == EventMaskKeyCtrlT {
+			w.specialEventC <- &EventKeyCtrlT{When: time.Now()}
+		}
+	case tcell.KeyCtrlU:
+		// 没有注册这个事件时, 使用内置的编辑动作: 剪切整行
+		if w.eventMask&EventMaskKeyCtrlU == EventMaskKeyCtrlU {
+			w.specialEventC <- &EventKeyCtrlU{When: time.Now()}
+		} else if !inputBlocked(w) && inputKillLine(w) {
+			showInput(w)
+		}
+	case tcell.KeyCtrlV:
+		if w.eventMask&EventMaskKeyCtrlV == EventMaskKeyCtrlV {
+			w.specialEventC <- &EventKeyCtrlV{When: time.Now()}
+		}
+	case tcell.KeyCtrlW:
+		// 没有注册这个事件时, 使用内置的编辑动作: 剪切前一个单词
+		if w.eventMask&EventMaskKeyCtrlW == EventMaskKeyCtrlW {
+			w.specialEventC <- &EventKeyCtrlW{When: time.Now()}
+		} else if !inputBlocked(w) && inputKillWordBackward(w) {
+			showInput(w)
+		}
+	case tcell.KeyCtrlX:
+		if w.eventMask&EventMaskKeyCtrlX == EventMaskKeyCtrlX {
+			w.specialEventC <- &EventKeyCtrlX{When: time.Now()}
+		}
+	case tcell.KeyCtrlY:
+		// 没有注册这个事件时, 使用内置的编辑动作: 粘贴最近剪切的内容
+		if w.eventMask&EventMaskKeyCtrlY == EventMaskKeyCtrlY {
+			w.specialEventC <- &EventKeyCtrlY{When: time.Now()}
+		} else if !inputBlocked(w) && inputYank(w) {
+			showInput(w)
+		}
+	case tcell.KeyCtrlZ:
+		if w.eventMask&EventMaskKeyCtrlZ == EventMaskKeyCtrlZ {
+			w.specialEventC <- &EventKeyCtrlZ{When: time.Now()}
+		}
+	case tcell.KeyCtrlUnderscore:
+		// 撤销
+		if !inputBlocked(w) && inputUndo(w) {
+			showInput(w)
+		}
+	}
+
+	// 忽略非普通rune字符
+	if event.Key() != tcell.KeyRune {
+		return
+	}
+
+	if inputBlocked(w) {
+		return
+	}
+
+	// Alt+_重做, Alt+Y在粘贴之后循环粘贴剪切环中更早的内容
+	if event.Modifiers()&tcell.ModAlt != 0 {
+		switch event.Rune() {
+		case '_':
+			if inputRedo(w) {
+				showInput(w)
+			}
+			return
+		case 'y':
+			if inputYankPop(w) {
+				showInput(w)
+			}
+			return
+		}
+	}
+
+	if inputInsert(w, []rune{event.Rune()}) {
+		showInput(w)
+	} else {
+		s.Beep()
+	}
+}
@@ -0,0 +1,179 @@
+package interactive
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+)
+
+// 输出行的折行方式
+type WrapMode int
+
+const (
+	// 不折行, 超出屏幕的部分通过横向滚动查看
+	WrapNone WrapMode = iota
+
+	// 在任意字符处折行
+	WrapChar
+
+	// 尽量在空格处折行, 单词比屏幕还宽时在字符处折行
+	WrapWord
+)
+
+// 折行时输出的位置由逻辑行loff和它的第woff个屏幕行表示, 不折行时woff总是0
+
+// 把一行输出展开为逐个字符及其样式
+func expandLine(line []interface{}) ([]rune, []tcell.Style) {
+	var rs []rune
+	var styles []tcell.Style
+	style := tcell.StyleDefault
+	for _, v := range line {
+		switch v := v.(type) {
+		case string:
+			for _, c := range v {
+				rs = append(rs, c)
+				styles = append(styles, style)
+			}
+		case tcell.Style:
+			style = v
+		}
+	}
+	return rs, styles
+}
+
+// 把一行字符按宽度width折成若干屏幕行, 空行也占一个屏幕行
+func wrapRows(rs []rune, width int, mode WrapMode) []inputRange {
+	var rows []inputRange
+	from := 0
+	for from < len(rs) {
+		to, rowWidth, lastSpace := from, 0, -1
+		for to < len(rs) {
+			cWidth := runewidth.RuneWidth(rs[to])
+			if rowWidth+cWidth > width {
+				break
+			}
+			if rs[to] == ' ' {
+				lastSpace = to
+			}
+			rowWidth += cWidth
+			to++
+		}
+		// 屏幕比一个字符还窄时也要前进
+		if to == from {
+			to++
+		}
+		if mode == WrapWord && to < len(rs) && rs[to] != ' ' && lastSpace >= from {
+			to = lastSpace + 1
+		}
+		rows = append(rows, inputRange{from: from, to: to})
+
+		// 在空格处折行时, 下一行开头的空格不显示
+		from = to
+		if mode == WrapWord {
+			for from < len(rs) && rs[from] == ' ' {
+				from++
+			}
+		}
+	}
+	if len(rows) == 0 {
+		rows = append(rows, inputRange{})
+	}
+	return rows
+}
+
+// 第i行输出占用的屏幕行数
+func lineRowCount(w *Win, i int) int {
+	if w.wrap == WrapNone {
+		return 1
+	}
+	rs, _ := expandLine(w.lines.at(i))
+	return len(wrapRows(rs, w.curmaxX+1, w.wrap))
+}
+
+// 位置(l1, r1)是否在(l2, r2)之前
+func outputOffLess(l1, r1, l2, r2 int) bool {
+	return l1 < l2 || (l1 == l2 && r1 < r2)
+}
+
+// 输出能滚动到的最后一个位置, 此时最后一行输出恰好在输出区域底部
+func maxOutputOff(w *Win) (int, int) {
+	rows := outputRows(w)
+	if w.wrap == WrapNone {
+		maxloff, _ := getMaxLoffAndOutputN(rows, w.lines.len())
+		return maxloff, 0
+	}
+	for i := w.lines.len() - 1; i >= 0; i-- {
+		n := lineRowCount(w, i)
+		if n >= rows {
+			return i, n - rows
+		}
+		rows -= n
+	}
+	return 0, 0
+}
+
+// 输出是否已经在最后一个位置
+func outputAtMax(w *Win) bool {
+	ml, mr := maxOutputOff(w)
+	return !outputOffLess(w.loff, w.woff, ml, mr)
+}
+
+// 把输出的位置限制在合法范围内, 行被删除或者窗口大小改变后调用
+func clampOutputOff(w *Win) {
+	if w.loff >= w.lines.len() {
+		w.loff, w.woff = w.lines.len(), 0
+	} else if n := lineRowCount(w, w.loff); w.woff >= n {
+		w.woff = n - 1
+	}
+	ml, mr := maxOutputOff(w)
+	if outputOffLess(ml, mr, w.loff, w.woff) {
+		w.loff, w.woff = ml, mr
+	}
+}
+
+// 按屏幕行滚动输出, n为正时向下滚动, 返回是否滚动了
+func scrollOutput(w *Win, n int) bool {
+	ml, mr := maxOutputOff(w)
+	moved := false
+	for ; n > 0 && outputOffLess(w.loff, w.woff, ml, mr); n-- {
+		w.woff++
+		if w.woff >= lineRowCount(w, w.loff) {
+			w.loff, w.woff = w.loff+1, 0
+		}
+		moved = true
+	}
+	for ; n < 0 && (w.loff != 0 || w.woff != 0); n++ {
+		if w.woff > 0 {
+			w.woff--
+		} else {
+			w.loff--
+			w.woff = lineRowCount(w, w.loff) - 1
+		}
+		moved = true
+	}
+	return moved
+}
+
+// 折行时画输出区域, 从第loff行的第woff个屏幕行开始, 不调用Show
+func drawWrappedOutput(w *Win) {
+	s := w.handler
+	y, rows := 0, outputRows(w)
+	for i := w.loff; i < w.lines.len() && y < rows; i++ {
+		rs, styles := expandLine(w.lines.at(i))
+		wrapped := wrapRows(rs, w.curmaxX+1, w.wrap)
+		from := 0
+		if i == w.loff {
+			from = w.woff
+		}
+		for _, r := range wrapped[from:] {
+			if y >= rows {
+				break
+			}
+			x := 0
+			for j := r.from; j < r.to; j++ {
+				s.SetContent(x, y, rs[j], nil, styles[j])
+				x += runewidth.RuneWidth(rs[j])
+			}
+			y++
+		}
+	}
+}
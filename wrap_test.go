@@ -0,0 +1,146 @@
+package interactive
+
+import (
+	"reflect"
+	"testing"
+)
+
+// 折行结果中每个屏幕行的文本
+func wrappedText(s string, width int, mode WrapMode) []string {
+	rs := []rune(s)
+	var rows []string
+	for _, r := range wrapRows(rs, width, mode) {
+		rows = append(rows, string(rs[r.from:r.to]))
+	}
+	return rows
+}
+
+func TestWrapRows(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		width int
+		mode  WrapMode
+		want  []string
+	}{
+		{"empty line takes a row", "", 4, WrapChar, []string{""}},
+		{"exact fit", "abcdef", 3, WrapChar, []string{"abc", "def"}},
+		{"remainder", "abcdefg", 3, WrapChar, []string{"abc", "def", "g"}},
+		{"wide rune at boundary", "ab中", 3, WrapChar, []string{"ab", "中"}},
+		{"wide rune fills row", "a中中", 4, WrapChar, []string{"a中", "中"}},
+		{"wide rune wider than screen", "中a", 1, WrapChar, []string{"中", "a"}},
+		{"char mode breaks words", "hello world", 8, WrapChar, []string{"hello wo", "rld"}},
+		{"char mode keeps spaces", "ab    cd", 4, WrapChar, []string{"ab  ", "  cd"}},
+		{"word break at space", "hello world", 8, WrapWord, []string{"hello ", "world"}},
+		{"word ends at row end", "abc def", 3, WrapWord, []string{"abc", "def"}},
+		{"word longer than width", "abcdefghij xy", 4, WrapWord, []string{"abcd", "efgh", "ij ", "xy"}},
+		{"run of spaces at break", "ab    cd", 4, WrapWord, []string{"ab  ", "cd"}},
+		{"only spaces", "      ", 4, WrapWord, []string{"    "}},
+		{"wide runes in words", "中文 中文", 5, WrapWord, []string{"中文 ", "中文"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrappedText(tt.in, tt.width, tt.mode); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrapRows(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+// 输出区域为width列rows行的窗口, 输入区域占一行
+func newWrapWin(width, rows int, mode WrapMode, lines ...string) *Win {
+	w := &Win{curmaxX: width - 1, curmaxY: rows, inputHeight: 1, wrap: mode}
+	for _, l := range lines {
+		w.lines.pushBack(outputLine{segs: []interface{}{l}})
+	}
+	return w
+}
+
+func TestLineRowCount(t *testing.T) {
+	tests := []struct {
+		line  string
+		width int
+		want  [3]int // WrapNone, WrapChar, WrapWord
+	}{
+		{"", 4, [3]int{1, 1, 1}},
+		{"abcd", 4, [3]int{1, 1, 1}},
+		{"abcdefghij", 4, [3]int{1, 3, 3}},
+		{"hello world", 8, [3]int{1, 2, 2}},
+		{"aa bb cc", 4, [3]int{1, 2, 3}},
+		{"中中中", 5, [3]int{1, 2, 2}},
+	}
+
+	for _, tt := range tests {
+		for k, mode := range []WrapMode{WrapNone, WrapChar, WrapWord} {
+			w := newWrapWin(tt.width, 10, mode, tt.line)
+			if got := lineRowCount(w, 0); got != tt.want[k] {
+				t.Errorf("lineRowCount(%q, width %d, mode %d) = %d, want %d", tt.line, tt.width, mode, got, tt.want[k])
+			}
+		}
+	}
+}
+
+func TestScrollOutput(t *testing.T) {
+	// 第一行折成3个屏幕行, 共5个屏幕行, 输出区域2行, 最后的位置是(1, 0)
+	lines := []string{"aa bb cc", "x", "y"}
+
+	tests := []struct {
+		name             string
+		loff, woff, n    int
+		wantLoff, wantWo int
+		wantMoved        bool
+	}{
+		{"down one row", 0, 0, 1, 0, 1, true},
+		{"down within line", 0, 0, 2, 0, 2, true},
+		{"down to next line", 0, 0, 3, 1, 0, true},
+		{"down stops at max", 0, 0, 10, 1, 0, true},
+		{"down at max", 1, 0, 1, 1, 0, false},
+		{"up into wrapped line", 1, 0, -1, 0, 2, true},
+		{"up stops at top", 1, 0, -10, 0, 0, true},
+		{"up at top", 0, 0, -1, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newWrapWin(4, 2, WrapWord, lines...)
+			w.loff, w.woff = tt.loff, tt.woff
+			moved := scrollOutput(w, tt.n)
+			if w.loff != tt.wantLoff || w.woff != tt.wantWo || moved != tt.wantMoved {
+				t.Errorf("scrollOutput(%d) from (%d, %d) = (%d, %d) %v, want (%d, %d) %v",
+					tt.n, tt.loff, tt.woff, w.loff, w.woff, moved, tt.wantLoff, tt.wantWo, tt.wantMoved)
+			}
+		})
+	}
+}
+
+func TestClampOutputOff(t *testing.T) {
+	tests := []struct {
+		name             string
+		mode             WrapMode
+		lines            []string
+		loff, woff       int
+		wantLoff, wantWo int
+	}{
+		{"empty buffer", WrapWord, nil, 3, 1, 0, 0},
+		{"past the end", WrapWord, []string{"aa bb cc", "x", "y"}, 5, 0, 1, 0},
+		{"past the max", WrapWord, []string{"aa bb cc", "x", "y"}, 2, 0, 1, 0},
+		{"row past the line", WrapWord, []string{"aa bb cc", "x", "y"}, 0, 7, 0, 2},
+		{"valid position kept", WrapWord, []string{"aa bb cc", "x", "y"}, 0, 1, 0, 1},
+		{"last line taller than screen", WrapWord, []string{"x", "aa bb cc"}, 5, 0, 1, 1},
+		{"no wrap past the end", WrapNone, []string{"aa bb cc", "x", "y"}, 3, 0, 1, 0},
+		{"no wrap fits on screen", WrapNone, []string{"x"}, 1, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newWrapWin(4, 2, tt.mode, tt.lines...)
+			w.loff, w.woff = tt.loff, tt.woff
+			clampOutputOff(w)
+			if w.loff != tt.wantLoff || w.woff != tt.wantWo {
+				t.Errorf("clampOutputOff from (%d, %d) = (%d, %d), want (%d, %d)",
+					tt.loff, tt.woff, w.loff, w.woff, tt.wantLoff, tt.wantWo)
+			}
+		})
+	}
+}